@@ -0,0 +1,193 @@
+package passwork
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/treasure33/passwork-client-go/internal/utils"
+)
+
+const (
+	defaultActivityPageSize     = 100
+	defaultActivityPollInterval = 30 * time.Second
+)
+
+// ListActivity Iterate over activity log events matching the filter
+// Pages are fetched lazily. In follow mode the iterator polls for new events
+// until the context is cancelled.
+func (c *Client) ListActivity(ctx context.Context, filter ActivityFilter) iter.Seq2[ActivityEvent, error] {
+	return func(yield func(ActivityEvent, error) bool) {
+		pageSize := filter.PageSize
+		if pageSize <= 0 {
+			pageSize = defaultActivityPageSize
+		}
+		pollInterval := filter.PollInterval
+		if pollInterval <= 0 {
+			pollInterval = defaultActivityPollInterval
+		}
+
+		// The next poll starts at the newest event seen and returns the events
+		// at that timestamp again. Their IDs are remembered to skip them,
+		// whatever order the server returns events in.
+		cursor := filter.From.Truncate(time.Second)
+		atCursor := make(map[string]struct{})
+		undated := make(map[string]struct{})
+
+		for {
+			window := filter
+			window.From = cursor
+			if filter.Follow {
+				// Close the window so events arriving while paging don't shift the pages
+				if now := time.Now().UTC().Truncate(time.Second); window.To.IsZero() || now.Before(window.To) {
+					window.To = now
+				}
+			}
+			nextCursor, nextAtCursor := cursor, maps.Clone(atCursor)
+
+			for page := 1; ; page++ {
+				request := window
+				call := &Call{
+					Operation: "ListActivity",
+					Method:    http.MethodGet,
+					URL:       c.activityURL(page, pageSize),
+					Request:   &request,
+				}
+				response, err := invoke(ctx, c, call, func(ctx context.Context) (ActivityListResponse, error) {
+					return c.getActivityPage(ctx, call)
+				})
+				if err != nil {
+					yield(ActivityEvent{}, err)
+					return
+				}
+
+				for _, event := range response.Data {
+					eventTime, err := event.Time()
+					switch {
+					case err != nil:
+						if _, ok := undated[event.Id]; ok {
+							continue
+						}
+						undated[event.Id] = struct{}{}
+					case eventTime.Before(cursor):
+						continue
+					case eventTime.Equal(cursor):
+						if _, ok := atCursor[event.Id]; ok {
+							continue
+						}
+					}
+
+					if err == nil {
+						if eventTime.After(nextCursor) {
+							nextCursor = eventTime
+							nextAtCursor = make(map[string]struct{})
+						}
+						if eventTime.Equal(nextCursor) {
+							nextAtCursor[event.Id] = struct{}{}
+						}
+					}
+
+					if !yield(event, nil) {
+						return
+					}
+				}
+
+				if len(response.Data) < pageSize {
+					break
+				}
+			}
+
+			if !filter.Follow {
+				return
+			}
+
+			cursor, atCursor = nextCursor, nextAtCursor
+
+			select {
+			case <-ctx.Done():
+				yield(ActivityEvent{}, ctx.Err())
+				return
+			case <-time.After(pollInterval):
+			}
+		}
+	}
+}
+
+// activityURL encodes the page into query parameters
+func (c *Client) activityURL(page, pageSize int) string {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(pageSize))
+
+	return fmt.Sprintf("%s/activity?%s", c.BaseURL, params.Encode())
+}
+
+// activityQuery adds the filter to the query parameters of rawURL
+func activityQuery(rawURL string, filter ActivityFilter) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Build query parameters
+	params := u.Query()
+	if filter.UserId != "" {
+		params.Set("userId", filter.UserId)
+	}
+	if filter.VaultId != "" {
+		params.Set("vaultId", filter.VaultId)
+	}
+	if filter.ItemId != "" {
+		params.Set("itemId", filter.ItemId)
+	}
+	if len(filter.Actions) > 0 {
+		params.Set("actions", strings.Join(filter.Actions, ","))
+	}
+	if !filter.From.IsZero() {
+		params.Set("from", filter.From.UTC().Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		params.Set("to", filter.To.UTC().Format(time.RFC3339))
+	}
+	u.RawQuery = params.Encode()
+
+	return u.String(), nil
+}
+
+func (c *Client) getActivityPage(ctx context.Context, call *Call) (ActivityListResponse, error) {
+	var responseObject ActivityListResponse
+
+	filter, err := callRequest[ActivityFilter](call)
+	if err != nil {
+		return responseObject, err
+	}
+	requestURL, err := activityQuery(call.URL, filter)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// HTTP request, encoding the filter as the interceptors left it
+	response, _, err := c.sendRequestContext(ctx, call.Method, requestURL, nil)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[ActivityListResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// API v1 doesn't return Status field
+	if responseObject.Status != "" && responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
@@ -0,0 +1,68 @@
+package passwork
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ActivityListResponse struct {
+	Status string
+	Code   string
+	Data   []ActivityEvent
+}
+
+// UnmarshalJSON implements custom unmarshaling to support both API v1 and v4 formats
+// API v1 uses "items" field, API v4 uses "Data" field
+func (a *ActivityListResponse) UnmarshalJSON(data []byte) error {
+	var v1Format struct {
+		Items []ActivityEvent `json:"items"`
+	}
+	if err := json.Unmarshal(data, &v1Format); err == nil && v1Format.Items != nil {
+		a.Data = v1Format.Items
+		return nil
+	}
+
+	type Alias ActivityListResponse
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+	return json.Unmarshal(data, aux)
+}
+
+type ActivityEvent struct {
+	Id        string
+	Action    string // e.g. itemViewed, itemEdited, vaultCreated
+	UserId    string
+	UserName  string
+	VaultId   string
+	FolderId  string
+	ItemId    string
+	ItemName  string
+	Ip        string
+	CreatedAt string // RFC 3339
+}
+
+// Time returns the parsed CreatedAt timestamp of the event
+func (e ActivityEvent) Time() (time.Time, error) {
+	return time.Parse(time.RFC3339, e.CreatedAt)
+}
+
+type ActivityFilter struct {
+	UserId  string
+	VaultId string
+	ItemId  string
+	Actions []string
+	From    time.Time
+	To      time.Time
+
+	// PageSize is the number of events requested per page, defaults to 100
+	PageSize int
+
+	// Follow keeps polling for new events once all existing events have been returned
+	Follow bool
+
+	// PollInterval is the delay between polls in follow mode, defaults to 30 seconds
+	PollInterval time.Duration
+}
@@ -0,0 +1,108 @@
+package passwork
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *PassworkTestSuite) TestActivity() {
+	suite.Run("List", func() {
+		filter := ActivityFilter{
+			VaultId:  suite.VaultId,
+			From:     time.Now().Add(-24 * time.Hour),
+			PageSize: 10,
+		}
+
+		count := 0
+		for event, err := range suite.client.ListActivity(context.Background(), filter) {
+			if !suite.NoError(err) {
+				break
+			}
+			suite.Equal(suite.VaultId, event.VaultId, "Event VaultId should match the filter VaultId.")
+
+			count++
+			if count == 25 {
+				break
+			}
+		}
+	})
+
+	suite.Run("Follow", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		filter := ActivityFilter{
+			VaultId:      suite.VaultId,
+			From:         time.Now(),
+			Follow:       true,
+			PollInterval: 500 * time.Millisecond,
+		}
+
+		var lastErr error
+		for _, err := range suite.client.ListActivity(ctx, filter) {
+			lastErr = err
+		}
+
+		suite.ErrorIs(lastErr, context.DeadlineExceeded, "Follow mode should run until the context is done.")
+	})
+}
+
+func TestListActivityFollowNewestFirst(t *testing.T) {
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	at := func(offset int) string { return base.Add(time.Duration(offset) * time.Second).Format(time.RFC3339) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	events := []ActivityEvent{{Id: "e2", CreatedAt: at(2)}, {Id: "e1", CreatedAt: at(1)}}
+	var windows []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		query := r.URL.Query()
+		windows = append(windows, query.Get("from")+" "+query.Get("page"))
+
+		from, _ := time.Parse(time.RFC3339, query.Get("from"))
+		var page []ActivityEvent
+		for _, event := range events {
+			if eventTime, _ := event.Time(); !eventTime.Before(from) {
+				page = append(page, event)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": page})
+
+		// New events arrive after the first poll, one sharing the newest timestamp
+		switch len(windows) {
+		case 3:
+			cancel()
+		case 1:
+			events = append([]ActivityEvent{{Id: "e4", CreatedAt: at(3)}, {Id: "e3", CreatedAt: at(2)}}, events...)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", time.Second)
+	filter := ActivityFilter{From: base, Follow: true, PollInterval: time.Millisecond}
+
+	var ids []string
+	for event, err := range client.ListActivity(ctx, filter) {
+		if err != nil {
+			require.ErrorIs(t, err, context.Canceled)
+			break
+		}
+		ids = append(ids, event.Id)
+	}
+
+	assert.Equal(t, []string{"e2", "e1", "e4", "e3"}, ids)
+	assert.Equal(t, []string{at(0) + " 1", at(2) + " 1", at(3) + " 1"}, windows)
+}
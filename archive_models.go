@@ -0,0 +1,117 @@
+package passwork
+
+import "encoding/json"
+
+// ArchiveVersion is the manifest version written by ExportVault
+const ArchiveVersion = 1
+
+type ArchiveManifest struct {
+	Version    int
+	ExportedAt string // RFC 3339
+	Vault      ArchiveVault
+	Folders    []ArchiveFolder
+	Items      []ArchiveItem
+}
+
+type ArchiveVault struct {
+	Id   string
+	Name string
+}
+
+type ArchiveFolder struct {
+	Id       string
+	ParentId string
+	Name     string
+	Path     []string // Folder names from the vault root down to this folder
+}
+
+type ArchiveItem struct {
+	Id                 string
+	FolderId           string
+	Name               string
+	Login              string
+	Password           Secret // Decrypted password
+	Url                string
+	Description        string
+	Color              int
+	Tags               []string
+	Custom             []PasswordCustomData
+	Attachments        []ArchiveAttachment
+	LastPasswordUpdate int
+	UpdatedAt          string
+}
+
+// MarshalJSON reveals the password and custom field values, the manifest
+// holds decrypted secrets and is only written into encrypted archives
+func (i ArchiveItem) MarshalJSON() ([]byte, error) {
+	type alias ArchiveItem
+	custom := make([]passwordCustomWire, len(i.Custom))
+	for j, c := range i.Custom {
+		custom[j] = passwordCustomWire{Name: c.Name, Value: c.Value.Reveal(), Type: c.Type}
+	}
+	return json.Marshal(struct {
+		alias
+		Password string
+		Custom   []passwordCustomWire
+	}{alias(i), i.Password.Reveal(), custom})
+}
+
+type ArchiveAttachment struct {
+	Id   string
+	Name string
+	File string // Path of the blob inside the archive
+}
+
+type ExportOptions struct {
+	// Passphrase used to derive the archive encryption key, required
+	Passphrase string
+
+	// Iterations of the key derivation function, defaults to 600000
+	Iterations int
+
+	// SkipAttachments excludes attachment blobs from the archive
+	SkipAttachments bool
+}
+
+type ConflictStrategy string
+
+const (
+	ConflictSkip      ConflictStrategy = "skip"      // Keep the existing object
+	ConflictOverwrite ConflictStrategy = "overwrite" // Replace the existing item, reuse existing folders
+	ConflictRename    ConflictStrategy = "rename"    // Create the object under a new unique name
+)
+
+type ImportOptions struct {
+	// Passphrase the archive was encrypted with, required
+	Passphrase string
+
+	// Conflict decides what happens when a folder or item already exists, defaults to ConflictSkip
+	Conflict ConflictStrategy
+
+	// DryRun reports what would be done without changing the target vault
+	DryRun bool
+
+	// Mapping of archive IDs to IDs in the target vault from a previous run.
+	// Objects contained in it are not imported again, which allows resuming
+	// an interrupted import by passing ImportReport.Mapping back in. Placeholder
+	// targets of a dry run are only honoured by another dry run.
+	Mapping map[string]string
+}
+
+type ImportReport struct {
+	Created []ImportResult
+	Skipped []ImportResult
+	Failed  []ImportResult
+
+	// Mapping of archive IDs to IDs in the target vault
+	Mapping map[string]string
+}
+
+type ImportResult struct {
+	Kind     string // folder, item
+	SourceId string
+	TargetId string
+	Name     string
+	Reason   string
+	Err      error
+}
@@ -0,0 +1,223 @@
+// Package audit reports weak, reused and old passwords without exposing them.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+const (
+	IssueWeak   = "weak"
+	IssueReused = "reused"
+	IssueOld    = "old"
+
+	defaultMinScore = 3
+)
+
+type Options struct {
+	// VaultIds to audit, defaults to all vaults the client can list
+	VaultIds []string
+
+	// MinScore is the lowest strength score that isn't reported as weak, defaults to 3
+	MinScore int
+
+	// MaxAge reports passwords not changed for longer, 0 disables the check
+	MaxAge time.Duration
+
+	// Words extends the dictionary of the strength check
+	Words []string
+
+	// Checks are run for every password in addition to the built-in ones
+	Checks []Check
+
+	// IncludeNames adds item names to the report. By default items are
+	// referenced by ID and path only.
+	IncludeNames bool
+}
+
+// Check inspects a single password. The returned message must not contain
+// the password or anything derived from it.
+type Check interface {
+	Name() string
+	Inspect(ctx context.Context, item Item, password []byte) (message string, found bool, err error)
+}
+
+// Item identifies an audited item without any of its content
+type Item struct {
+	Id                 string     `json:"id"`
+	VaultId            string     `json:"vaultId"`
+	Name               string     `json:"name,omitempty"`
+	Path               string     `json:"path"`
+	LastPasswordUpdate *time.Time `json:"lastPasswordUpdate,omitempty"`
+}
+
+// Run audits all items of the vaults. Passwords are fetched one at a time
+// bypassing the client's cache and offline snapshot, reuse is detected
+// through hashes salted with a random key that only lives for the duration of
+// the run. Passwords are cleared after inspection, only the copy made by the
+// strength check is left to the garbage collector.
+func Run(ctx context.Context, client *passwork.Client, opts Options) (Report, error) {
+	report := Report{GeneratedAt: time.Now().UTC(), Summary: make(map[string]int)}
+	client = client.Uncached()
+	if opts.MinScore <= 0 {
+		opts.MinScore = defaultMinScore
+	}
+
+	vaultIds := opts.VaultIds
+	if len(vaultIds) == 0 {
+		vaults, err := client.ListVaultsContext(ctx)
+		if err != nil {
+			return report, fmt.Errorf("audit: list vaults: %w", err)
+		}
+		for _, vault := range vaults.Data {
+			vaultIds = append(vaultIds, vault.Id)
+		}
+	}
+	report.VaultIds = vaultIds
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return report, err
+	}
+	defer clear(key)
+	reuse := make(map[string][]int)
+
+	for _, vaultId := range vaultIds {
+		items, err := client.SearchPasswordContext(ctx, passwork.PasswordSearchRequest{VaultId: vaultId})
+		if err != nil {
+			return report, fmt.Errorf("audit: list items of vault %s: %w", vaultId, err)
+		}
+
+		for _, summary := range items.Data {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+
+			result, hash, err := audit(ctx, client, summary.Id, key, opts)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return report, ctxErr
+			}
+			if err != nil {
+				report.Errors = append(report.Errors, ItemError{ItemId: summary.Id, Error: err.Error()})
+				continue
+			}
+			if hash != "" {
+				reuse[hash] = append(reuse[hash], len(report.Items))
+			}
+			report.Items = append(report.Items, result)
+		}
+	}
+
+	for _, indexes := range reuse {
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			for _, j := range indexes {
+				if i != j {
+					report.Items[i].ReusedWith = append(report.Items[i].ReusedWith, report.Items[j].Id)
+				}
+			}
+			slices.Sort(report.Items[i].ReusedWith)
+			report.Items[i].Issues = append(report.Items[i].Issues, Issue{
+				Check:   IssueReused,
+				Message: fmt.Sprintf("same password as %d other items", len(indexes)-1),
+			})
+		}
+	}
+
+	for _, item := range report.Items {
+		for _, issue := range item.Issues {
+			report.Summary[issue.Check]++
+		}
+	}
+	report.Summary["items"] = len(report.Items)
+
+	return report, nil
+}
+
+// audit inspects a single item and returns the keyed hash of its password
+func audit(ctx context.Context, client *passwork.Client, itemId string, key []byte, opts Options) (ItemReport, string, error) {
+	response, err := client.GetPasswordContext(ctx, itemId)
+	if err != nil {
+		return ItemReport{}, "", err
+	}
+	data := response.Data
+
+	result := ItemReport{Item: Item{
+		Id:      data.Id,
+		VaultId: data.VaultId,
+		Name:    data.Name,
+		Path:    itemPath(data),
+	}}
+	if !opts.IncludeNames {
+		result.Name = ""
+	}
+	if data.LastPasswordUpdate > 0 {
+		updated := time.Unix(int64(data.LastPasswordUpdate), 0).UTC()
+		result.LastPasswordUpdate = &updated
+	}
+
+	if opts.MaxAge > 0 && result.LastPasswordUpdate != nil {
+		if age := time.Since(*result.LastPasswordUpdate); age > opts.MaxAge {
+			result.Issues = append(result.Issues, Issue{
+				Check:   IssueOld,
+				Message: fmt.Sprintf("password not changed for %d days", int(age.Hours()/24)),
+			})
+		}
+	}
+
+	defer data.CryptedPassword.Destroy()
+	encoded := data.CryptedPassword.Bytes()
+	password := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	defer clear(password)
+	n, err := base64.StdEncoding.Decode(password, encoded)
+	if err != nil {
+		return result, "", fmt.Errorf("decode password: %w", err)
+	}
+	password = password[:n]
+	if len(password) == 0 {
+		return result, "", nil
+	}
+
+	strength := MeasureStrength(string(password), opts.Words)
+	result.Entropy, result.Score, result.Patterns = strength.Entropy, strength.Score, strength.Patterns
+	if strength.Score < opts.MinScore {
+		result.Issues = append(result.Issues, Issue{
+			Check:   IssueWeak,
+			Message: fmt.Sprintf("strength score %d of 4, about %.0f bits", strength.Score, strength.Entropy),
+		})
+	}
+
+	for _, check := range opts.Checks {
+		message, found, err := check.Inspect(ctx, result.Item, password)
+		if err != nil {
+			return result, "", fmt.Errorf("%s: %w", check.Name(), err)
+		}
+		if found {
+			result.Issues = append(result.Issues, Issue{Check: check.Name(), Message: message})
+		}
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(password)
+	return result, string(mac.Sum(nil)), nil
+}
+
+// itemPath joins the names of the vault and folders containing the item
+func itemPath(item passwork.PasswordResponseData) string {
+	path := make([]string, 0, len(item.Path))
+	for _, segment := range item.Path {
+		path = append(path, segment.Name)
+	}
+	return strings.Join(path, "/")
+}
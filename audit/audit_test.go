@@ -0,0 +1,273 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+var auditTestPasswords = map[string]string{
+	"pw1": "P@ssw0rd2024",
+	"pw2": "kX9#vQ2$mL7!rT4&wZ8^",
+	"pw3": "kX9#vQ2$mL7!rT4&wZ8^",
+	"pw4": "Tr0ub4dor&3-horse-battery-staple",
+}
+
+func newAuditTestClient(t *testing.T) *passwork.Client {
+	old := int(time.Now().Add(-400 * 24 * time.Hour).Unix())
+	items := make(map[string]passwork.PasswordResponseData)
+	for id, password := range auditTestPasswords {
+		items[id] = passwork.PasswordResponseData{
+			Id:                 id,
+			VaultId:            "v1",
+			Name:               "item " + id,
+			CryptedPassword:    passwork.NewSecret(base64.StdEncoding.EncodeToString([]byte(password))),
+			LastPasswordUpdate: int(time.Now().Unix()),
+			Path:               []passwork.PathData{{Type: "vault", Name: "infra"}, {Type: "folder", Name: "db"}},
+		}
+	}
+	pw4 := items["pw4"]
+	pw4.LastPasswordUpdate = old
+	items["pw4"] = pw4
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/vaults":
+			w.Write([]byte(`{"status":"success","data":[{"id":"v1","name":"infra"}]}`))
+		case r.URL.Path == "/items/search":
+			summaries := make([]passwork.PasswordResponseData, 0, len(items))
+			for _, id := range []string{"pw1", "pw2", "pw3", "pw4"} {
+				summary := items[id]
+				summary.CryptedPassword = passwork.Secret{}
+				summaries = append(summaries, summary)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": summaries})
+		case strings.HasPrefix(r.URL.Path, "/items/"):
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": revealed(items[strings.TrimPrefix(r.URL.Path, "/items/")])})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return passwork.NewClient(server.URL, "key", time.Second)
+}
+
+func TestRun(t *testing.T) {
+	client := newAuditTestClient(t)
+
+	report, err := Run(context.Background(), client, Options{MaxAge: 365 * 24 * time.Hour, IncludeNames: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, report.VaultIds)
+	require.Len(t, report.Items, 4)
+
+	byId := make(map[string]ItemReport)
+	for _, item := range report.Items {
+		byId[item.Id] = item
+	}
+
+	assert.True(t, byId["pw1"].HasIssue(IssueWeak))
+	assert.Contains(t, byId["pw1"].Patterns, "dictionary")
+	assert.False(t, byId["pw2"].HasIssue(IssueWeak))
+	assert.Equal(t, "infra/db", byId["pw1"].Path)
+
+	assert.True(t, byId["pw2"].HasIssue(IssueReused))
+	assert.Equal(t, []string{"pw3"}, byId["pw2"].ReusedWith)
+	assert.Equal(t, []string{"pw2"}, byId["pw3"].ReusedWith)
+	assert.False(t, byId["pw1"].HasIssue(IssueReused))
+
+	assert.True(t, byId["pw4"].HasIssue(IssueOld))
+	assert.False(t, byId["pw1"].HasIssue(IssueOld))
+
+	assert.Equal(t, map[string]int{"items": 4, IssueWeak: 1, IssueReused: 2, IssueOld: 1}, report.Summary)
+
+	t.Run("NoSecrets", func(t *testing.T) {
+		var jsonReport, textReport bytes.Buffer
+		require.NoError(t, report.WriteJSON(&jsonReport))
+		require.NoError(t, report.WriteText(&textReport))
+		assert.Contains(t, textReport.String(), "item pw1")
+
+		for _, password := range auditTestPasswords {
+			assert.NotContains(t, jsonReport.String(), password)
+			assert.NotContains(t, textReport.String(), password)
+			assert.NotContains(t, jsonReport.String(), base64.StdEncoding.EncodeToString([]byte(password)))
+		}
+	})
+}
+
+func TestRunCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/items/search" {
+			w.Write([]byte(`{"status":"success","data":[{"id":"pw1","vaultId":"v1"}]}`))
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := passwork.NewClient(server.URL, "key", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := Run(ctx, client, Options{VaultIds: []string{"v1"}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "The audit should stop when ctx is done.")
+}
+
+type lengthCheck struct{}
+
+func (lengthCheck) Name() string { return "short" }
+
+func (lengthCheck) Inspect(ctx context.Context, item Item, password []byte) (string, bool, error) {
+	return "shorter than 16 characters", len(password) < 16, nil
+}
+
+func TestRunChecks(t *testing.T) {
+	client := newAuditTestClient(t)
+
+	report, err := Run(context.Background(), client, Options{VaultIds: []string{"v1"}, Checks: []Check{lengthCheck{}}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Summary["short"])
+}
+
+func TestMeasureStrength(t *testing.T) {
+	cases := []struct {
+		password string
+		maxScore int
+		minScore int
+	}{
+		{"password", 0, 0},
+		{"qwerty123", 0, 0},
+		{"aaaaaaaaaaaa", 0, 0},
+		{"abcdefgh2024", 1, 0},
+		{"Summer2024!", 2, 0},
+		{"kX9#vQ2$mL7!rT4&wZ8^", 4, 4},
+	}
+	for _, c := range cases {
+		strength := MeasureStrength(c.password, nil)
+		assert.LessOrEqual(t, strength.Score, c.maxScore, c.password)
+		assert.GreaterOrEqual(t, strength.Score, c.minScore, c.password)
+	}
+
+	assert.Contains(t, MeasureStrength("acme-corp-2000", []string{"acme"}).Patterns, "dictionary")
+	assert.Equal(t, Strength{}, MeasureStrength("", nil))
+}
+
+func writeBreachFile(t *testing.T, passwords map[string]int, filler int) string {
+	var lines []string
+	for password, count := range passwords {
+		lines = append(lines, fmt.Sprintf("%X:%d", sha1.Sum([]byte(password)), count))
+	}
+	for i := range filler {
+		lines = append(lines, fmt.Sprintf("%X:%d", sha1.Sum([]byte(fmt.Sprintf("filler-%d", i))), i+1))
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+	return path
+}
+
+func TestBreachFile(t *testing.T) {
+	breached := map[string]int{"P@ssw0rd2024": 42, "letmein": 1000}
+	path := writeBreachFile(t, breached, 5000)
+
+	database, err := OpenBreachFile(path)
+	require.NoError(t, err)
+	defer database.Close()
+
+	for password, count := range breached {
+		found, err := database.Lookup(sha1.Sum([]byte(password)))
+		require.NoError(t, err)
+		assert.Equal(t, count, found, password)
+	}
+	for i := range 5000 {
+		found, err := database.Lookup(sha1.Sum([]byte(fmt.Sprintf("filler-%d", i))))
+		require.NoError(t, err)
+		require.Equal(t, i+1, found)
+	}
+
+	found, err := database.Lookup(sha1.Sum([]byte("kX9#vQ2$mL7!rT4&wZ8^")))
+	require.NoError(t, err)
+	assert.Zero(t, found)
+	found, err = database.Lookup([sha1.Size]byte{})
+	require.NoError(t, err)
+	assert.Zero(t, found)
+	found, err = database.Lookup([sha1.Size]byte{0xff, 0xff, 0xff, 0xff})
+	require.NoError(t, err)
+	assert.Zero(t, found)
+}
+
+func TestBreachRanges(t *testing.T) {
+	dir := t.TempDir()
+	sum := fmt.Sprintf("%X", sha1.Sum([]byte("P@ssw0rd2024")))
+	content := "0000000000000000000000000000000000A:1\r\n" + sum[5:] + ":42\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:3\r\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, sum[:5]+".txt"), []byte(content), 0o600))
+
+	database, err := OpenBreachRanges(dir)
+	require.NoError(t, err)
+
+	found, err := database.Lookup(sha1.Sum([]byte("P@ssw0rd2024")))
+	require.NoError(t, err)
+	assert.Equal(t, 42, found)
+
+	found, err = database.Lookup(sha1.Sum([]byte("not breached")))
+	require.NoError(t, err)
+	assert.Zero(t, found)
+}
+
+func TestRunBreachCheck(t *testing.T) {
+	client := newAuditTestClient(t)
+	database, err := OpenBreachFile(writeBreachFile(t, map[string]int{"P@ssw0rd2024": 42}, 100))
+	require.NoError(t, err)
+	defer database.Close()
+
+	report, err := Run(context.Background(), client, Options{Checks: []Check{BreachCheck{Database: database}}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Summary[IssueBreached])
+
+	var jsonReport bytes.Buffer
+	require.NoError(t, report.WriteJSON(&jsonReport))
+	assert.NotContains(t, jsonReport.String(), "item pw")
+	assert.NotContains(t, jsonReport.String(), fmt.Sprintf("%X", sha1.Sum([]byte("P@ssw0rd2024"))))
+
+	for _, item := range report.Items {
+		assert.Equal(t, item.Id == "pw1", item.HasIssue(IssueBreached), item.Id)
+		assert.Empty(t, item.Name)
+		assert.Equal(t, "infra/db", item.Path)
+	}
+}
+
+// revealed encodes an item the way the API sends it, with secrets in clear
+func revealed(item passwork.PasswordResponseData) map[string]any {
+	data, _ := json.Marshal(item)
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+
+	fields["CryptedPassword"] = item.CryptedPassword.Reveal()
+	custom := make([]map[string]string, len(item.Custom))
+	for i, c := range item.Custom {
+		custom[i] = map[string]string{"name": c.Name, "value": c.Value.Reveal(), "type": c.Type}
+	}
+	fields["Custom"] = custom
+	return fields
+}
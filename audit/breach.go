@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const IssueBreached = "breached"
+
+const breachLineBuffer = 128
+
+// BreachDatabase looks up SHA-1 hashes of passwords in Pwned Passwords data
+type BreachDatabase interface {
+	// Lookup returns how often the hash was seen in breaches, 0 if never
+	Lookup(sum [sha1.Size]byte) (int, error)
+}
+
+// BreachCheck reports passwords found in a local breach database. Only the
+// hash is looked up, nothing leaves the machine.
+type BreachCheck struct {
+	Database BreachDatabase
+}
+
+func (BreachCheck) Name() string {
+	return IssueBreached
+}
+
+func (c BreachCheck) Inspect(ctx context.Context, item Item, password []byte) (string, bool, error) {
+	count, err := c.Database.Lookup(sha1.Sum(password))
+	if err != nil || count == 0 {
+		return "", false, err
+	}
+	return fmt.Sprintf("found %d times in known breaches", count), true, nil
+}
+
+// BreachFile is a single file of "HASH:COUNT" lines sorted by hash, as
+// published by Have I Been Pwned ordered by hash. Lookups binary search the
+// file on disk instead of loading it.
+type BreachFile struct {
+	file *os.File
+	size int64
+}
+
+// OpenBreachFile opens a sorted hash file
+func OpenBreachFile(path string) (*BreachFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &BreachFile{file: file, size: info.Size()}, nil
+}
+
+func (b *BreachFile) Close() error {
+	return b.file.Close()
+}
+
+func (b *BreachFile) Lookup(sum [sha1.Size]byte) (int, error) {
+	target := []byte(hex.EncodeToString(sum[:]))
+
+	// Invariant: a matching line starts within [lo, hi)
+	lo, hi := int64(0), b.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := b.lineAt(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi || line == nil {
+			hi = mid
+			continue
+		}
+
+		hash, count, err := parseBreachLine(line)
+		if err != nil {
+			return 0, fmt.Errorf("audit: breach file at offset %d: %w", start, err)
+		}
+		switch cmp := bytes.Compare(bytes.ToLower(hash), target); {
+		case cmp == 0:
+			return count, nil
+		case cmp < 0:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineAt returns the first line starting at or after pos, line is nil at the end of the file
+func (b *BreachFile) lineAt(pos int64) (int64, []byte, error) {
+	start := pos
+	if pos > 0 {
+		// Skip the rest of the line pos-1 belongs to
+		buf := make([]byte, breachLineBuffer)
+		for offset := pos - 1; ; offset += int64(len(buf)) {
+			n, err := b.file.ReadAt(buf, offset)
+			if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+				start = offset + int64(i) + 1
+				break
+			}
+			if err == io.EOF {
+				return b.size, nil, nil
+			}
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+
+	var line []byte
+	buf := make([]byte, breachLineBuffer)
+	for offset := start; ; offset += int64(len(buf)) {
+		n, err := b.file.ReadAt(buf, offset)
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return start, append(line, buf[:i]...), nil
+		}
+		line = append(line, buf[:n]...)
+		if err == io.EOF {
+			if len(line) == 0 {
+				return start, nil, nil
+			}
+			return start, line, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// BreachRanges is a directory of range files named after the first five hex
+// characters of the hash, e.g. 21BD1.txt, each holding sorted "SUFFIX:COUNT"
+// lines. This is the layout written by the Pwned Passwords downloader.
+type BreachRanges struct {
+	dir string
+}
+
+// OpenBreachRanges opens a directory of range files
+func OpenBreachRanges(dir string) (*BreachRanges, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("audit: %s is not a directory", dir)
+	}
+	return &BreachRanges{dir: dir}, nil
+}
+
+func (r *BreachRanges) Lookup(sum [sha1.Size]byte) (int, error) {
+	hash := bytes.ToUpper([]byte(hex.EncodeToString(sum[:])))
+	prefix, suffix := string(hash[:5]), hash[5:]
+
+	data, err := os.ReadFile(filepath.Join(r.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	i := sort.Search(len(lines), func(i int) bool {
+		return bytes.Compare(bytes.ToUpper(lineHash(lines[i])), suffix) >= 0
+	})
+	if i == len(lines) {
+		return 0, nil
+	}
+	found, count, err := parseBreachLine(lines[i])
+	if err != nil {
+		return 0, fmt.Errorf("audit: range file %s: %w", prefix, err)
+	}
+	if !bytes.EqualFold(found, suffix) {
+		return 0, nil
+	}
+	return count, nil
+}
+
+func lineHash(line []byte) []byte {
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+func parseBreachLine(line []byte) ([]byte, int, error) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	hash, count, ok := bytes.Cut(line, []byte(":"))
+	if !ok {
+		return nil, 0, errors.New("malformed line")
+	}
+	n, err := strconv.Atoi(string(bytes.TrimSpace(count)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("malformed count: %w", err)
+	}
+	return hash, n, nil
+}
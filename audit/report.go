@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// Report is the result of an audit, it never contains secrets
+type Report struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	VaultIds    []string       `json:"vaultIds"`
+	Summary     map[string]int `json:"summary"` // Number of items per issue and in total
+	Items       []ItemReport   `json:"items"`
+	Errors      []ItemError    `json:"errors,omitempty"`
+}
+
+type ItemReport struct {
+	Item
+	Entropy    float64  `json:"entropy"`
+	Score      int      `json:"score"`
+	Patterns   []string `json:"patterns,omitempty"`
+	ReusedWith []string `json:"reusedWith,omitempty"` // IDs of items with the same password
+	Issues     []Issue  `json:"issues,omitempty"`
+}
+
+type Issue struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// ItemError is an item that couldn't be audited
+type ItemError struct {
+	ItemId string `json:"itemId"`
+	Error  string `json:"error"`
+}
+
+// HasIssue reports whether the item has an issue of the given check
+func (r ItemReport) HasIssue(check string) bool {
+	return slices.ContainsFunc(r.Issues, func(issue Issue) bool { return issue.Check == check })
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes a human readable summary listing items with issues
+func (r Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Audit of %d items in %d vaults, %s\n", r.Summary["items"], len(r.VaultIds), r.GeneratedAt.Format(time.RFC3339))
+
+	checks := make([]string, 0, len(r.Summary))
+	for check := range r.Summary {
+		if check != "items" {
+			checks = append(checks, check)
+		}
+	}
+	slices.Sort(checks)
+	for _, check := range checks {
+		fmt.Fprintf(w, "  %-10s %d\n", check, r.Summary[check])
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := false
+	for _, item := range r.Items {
+		name := item.Name
+		if name == "" {
+			name = item.Id
+		}
+		for _, issue := range item.Issues {
+			if !header {
+				fmt.Fprintln(tw, "\nITEM\tPATH\tCHECK\tDETAILS")
+				header = true
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, item.Path, issue.Check, issue.Message)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, itemError := range r.Errors {
+		fmt.Fprintf(w, "error: item %s: %s\n", itemError.ItemId, itemError.Error)
+	}
+	return nil
+}
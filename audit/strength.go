@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Strength is an estimate of how hard a password is to guess
+type Strength struct {
+	Entropy  float64  // Estimated bits after discounting patterns
+	Score    int      // 0 (very weak) to 4 (strong)
+	Patterns []string // Kinds of patterns found, e.g. dictionary, sequence
+}
+
+// Common passwords and words, matched case-insensitively and with leetspeak undone
+var commonWords = []string{
+	"password", "passwort", "qwerty", "azerty", "letmein", "welcome", "admin", "administrator",
+	"root", "login", "master", "secret", "monkey", "dragon", "iloveyou", "sunshine", "princess",
+	"football", "baseball", "soccer", "hockey", "shadow", "superman", "batman", "trustno1",
+	"starwars", "whatever", "freedom", "michael", "jennifer", "charlie", "computer", "internet",
+	"changeme", "default", "guest", "test", "summer", "winter", "spring", "autumn", "hello",
+	"access", "mustang", "killer", "pepper", "ginger", "cheese", "flower", "orange", "banana",
+	"company", "database", "server", "service", "passwork", "user", "temp", "backup",
+}
+
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890", "qwertzuiop", "azertyuiop"}
+
+var leetspeak = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+// MeasureStrength estimates the strength of password. words extends the
+// built-in dictionary, e.g. with company or product names.
+func MeasureStrength(password string, words []string) Strength {
+	var strength Strength
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return strength
+	}
+
+	perChar := math.Log2(float64(charsetSize(runes)))
+
+	// Characters covered by a pattern count as a single guess from a small space
+	covered := make([]bool, len(runes))
+	var bits float64
+	mark := func(kind string, start, length int, guesses float64) {
+		newly := 0
+		for i := start; i < start+length; i++ {
+			if !covered[i] {
+				covered[i] = true
+				newly++
+			}
+		}
+		if newly == 0 {
+			return
+		}
+		bits += math.Log2(guesses) * float64(newly) / float64(length)
+		if !slices.Contains(strength.Patterns, kind) {
+			strength.Patterns = append(strength.Patterns, kind)
+		}
+	}
+
+	normalized := []rune(leetspeak.Replace(strings.ToLower(password)))
+	if len(normalized) == len(runes) {
+		for _, word := range append(commonWords, words...) {
+			word = strings.ToLower(word)
+			if len(word) < 3 {
+				continue
+			}
+			needle := []rune(word)
+			for i := 0; i+len(needle) <= len(normalized); i++ {
+				if slices.Equal(normalized[i:i+len(needle)], needle) {
+					mark("dictionary", i, len(needle), float64(len(commonWords)+len(words))*2)
+				}
+			}
+		}
+	}
+
+	lower := []rune(strings.ToLower(password))
+	for start := 0; start < len(lower); {
+		length := runLength(lower[start:], func(a, b rune) bool { return a == b })
+		if length >= 3 {
+			mark("repeat", start, length, float64(charsetSize(runes))*float64(length))
+		}
+		start += max(length, 1)
+	}
+	for start := 0; start < len(lower); {
+		length := runLength(lower[start:], func(a, b rune) bool { return b-a == 1 || a-b == 1 })
+		if length >= 3 {
+			mark("sequence", start, length, 26*2*float64(length))
+		}
+		start += max(length-1, 1)
+	}
+	for start := 0; start < len(lower); {
+		length := runLength(lower[start:], adjacentOnKeyboard)
+		if length >= 4 {
+			mark("keyboard", start, length, 100*float64(length))
+		}
+		start += max(length-1, 1)
+	}
+	for start := 0; start+4 <= len(runes); start++ {
+		if year := string(runes[start : start+4]); (strings.HasPrefix(year, "19") || strings.HasPrefix(year, "20")) && isDigits(year) {
+			mark("date", start, 4, 200)
+		}
+	}
+
+	for i := range runes {
+		if !covered[i] {
+			bits += perChar
+		}
+	}
+
+	strength.Entropy = math.Round(bits*10) / 10
+	switch {
+	case bits < 28:
+		strength.Score = 0
+	case bits < 36:
+		strength.Score = 1
+	case bits < 60:
+		strength.Score = 2
+	case bits < 80:
+		strength.Score = 3
+	default:
+		strength.Score = 4
+	}
+	return strength
+}
+
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			size += class.size
+		}
+	}
+	return size
+}
+
+// runLength returns how many runes from the start form a chain where each
+// neighbouring pair satisfies next
+func runLength(runes []rune, next func(a, b rune) bool) int {
+	if len(runes) == 0 {
+		return 0
+	}
+	length := 1
+	for length < len(runes) && next(runes[length-1], runes[length]) {
+		length++
+	}
+	return length
+}
+
+func adjacentOnKeyboard(a, b rune) bool {
+	for _, row := range keyboardRows {
+		i, j := strings.IndexRune(row, a), strings.IndexRune(row, b)
+		if i >= 0 && j >= 0 && (i-j == 1 || j-i == 1) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
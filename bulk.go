@@ -0,0 +1,109 @@
+package passwork
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+const defaultBulkWorkers = 4
+
+// BulkAdd Add passwords concurrently, returning one result per request
+func (c *Client) BulkAdd(ctx context.Context, requests iter.Seq[PasswordRequest], opts BulkOptions) []BulkResult[PasswordResponse] {
+	return runBulk(ctx, requests, opts, c.addPasswordContext)
+}
+
+// BulkEdit Edit passwords concurrently, returning one result per request
+func (c *Client) BulkEdit(ctx context.Context, requests iter.Seq[BulkEditRequest], opts BulkOptions) []BulkResult[PasswordResponse] {
+	return runBulk(ctx, requests, opts, func(ctx context.Context, request BulkEditRequest) (PasswordResponse, error) {
+		return c.editPasswordContext(ctx, request.Id, request.Request)
+	})
+}
+
+// BulkDelete Delete passwords concurrently, returning one result per ID
+func (c *Client) BulkDelete(ctx context.Context, pwIds iter.Seq[string], opts BulkOptions) []BulkResult[DeleteResponse] {
+	return runBulk(ctx, pwIds, opts, c.deletePasswordContext)
+}
+
+// runBulk feeds requests to a pool of workers. Failures don't stop other
+// requests, once ctx is done queued requests are marked cancelled and no
+// more requests are pulled from the iterator.
+func runBulk[Req any, Resp any](ctx context.Context, requests iter.Seq[Req], opts BulkOptions, do func(context.Context, Req) (Resp, error)) []BulkResult[Resp] {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	var limiter <-chan time.Time
+	if interval := opts.interval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	type job struct {
+		index   int
+		request Req
+	}
+	jobs := make(chan job)
+
+	var mu sync.Mutex
+	var results []BulkResult[Resp]
+	record := func(result BulkResult[Resp]) {
+		mu.Lock()
+		defer mu.Unlock()
+		for len(results) <= result.Index {
+			results = append(results, BulkResult[Resp]{})
+		}
+		results[result.Index] = result
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				result := BulkResult[Resp]{Index: j.index}
+
+				if limiter != nil {
+					select {
+					case <-limiter:
+					case <-ctx.Done():
+					}
+				}
+				if err := ctx.Err(); err != nil {
+					result.Status, result.Err = BulkCancelled, err
+					record(result)
+					continue
+				}
+
+				result.Response, result.Err = do(ctx, j.request)
+				if result.Err != nil {
+					result.Status = BulkFailed
+				} else {
+					result.Status = BulkSucceeded
+				}
+				record(result)
+			}
+		}()
+	}
+
+	index := 0
+	for request := range requests {
+		select {
+		case jobs <- job{index: index, request: request}:
+		case <-ctx.Done():
+			record(BulkResult[Resp]{Index: index, Status: BulkCancelled, Err: ctx.Err()})
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		index++
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
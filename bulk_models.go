@@ -0,0 +1,40 @@
+package passwork
+
+import "time"
+
+type BulkOptions struct {
+	// Workers is the number of concurrent requests, defaults to 4
+	Workers int
+
+	// RateLimit caps the requests per second across all workers, 0 disables it
+	RateLimit float64
+}
+
+type BulkStatus string
+
+const (
+	BulkSucceeded BulkStatus = "succeeded"
+	BulkFailed    BulkStatus = "failed"
+	BulkCancelled BulkStatus = "cancelled" // Not attempted because the context was done
+)
+
+type BulkEditRequest struct {
+	Id      string
+	Request PasswordRequest
+}
+
+// BulkResult is the outcome of a single request of a bulk operation.
+// Index is the position of the request in the input sequence.
+type BulkResult[T any] struct {
+	Index    int
+	Status   BulkStatus
+	Response T
+	Err      error
+}
+
+func (o BulkOptions) interval() time.Duration {
+	if o.RateLimit <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / o.RateLimit)
+}
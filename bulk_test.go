@@ -0,0 +1,112 @@
+package passwork
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *PassworkTestSuite) TestBulk() {
+	var ids []string
+
+	suite.Run("Add", func() {
+		requests := []PasswordRequest{
+			{Name: "provider-test-bulk-1", VaultId: suite.VaultId, CryptedPassword: NewSecret("cHJvdmlkZXItdGVzdC1wYXNzd29yZA==")},
+			{Name: "provider-test-bulk-2", VaultId: suite.VaultId, CryptedPassword: NewSecret("cHJvdmlkZXItdGVzdC1wYXNzd29yZA==")},
+			{Name: "provider-test-bulk-3", VaultId: suite.VaultId, CryptedPassword: NewSecret("cHJvdmlkZXItdGVzdC1wYXNzd29yZA==")},
+		}
+
+		results := suite.client.BulkAdd(context.Background(), slices.Values(requests), BulkOptions{Workers: 2, RateLimit: 10})
+
+		suite.Len(results, len(requests), "BulkAdd() should return one result per request.")
+		for i, result := range results {
+			if suite.NoError(result.Err) {
+				suite.Equal(BulkSucceeded, result.Status, "BulkAdd() result should be succeeded.")
+				suite.Equal(requests[i].Name, result.Response.Data.Name, "Results should be in request order.")
+				ids = append(ids, result.Response.Data.Id)
+			}
+		}
+	})
+
+	suite.Run("Edit", func() {
+		var requests []BulkEditRequest
+		for _, id := range ids {
+			requests = append(requests, BulkEditRequest{Id: id, Request: PasswordRequest{Name: "provider-test-bulk-edited", VaultId: suite.VaultId, Color: 3}})
+		}
+		requests = append(requests, BulkEditRequest{Id: "does-not-exist", Request: PasswordRequest{Name: "x", VaultId: suite.VaultId}})
+
+		results := suite.client.BulkEdit(context.Background(), slices.Values(requests), BulkOptions{})
+
+		for _, result := range results[:len(ids)] {
+			if suite.NoError(result.Err) {
+				suite.Equal(3, result.Response.Data.Color, "BulkEdit() should apply the request.")
+			}
+		}
+		suite.Equal(BulkFailed, results[len(ids)].Status, "A failing request should not abort the others.")
+	})
+
+	suite.Run("Delete", func() {
+		results := suite.client.BulkDelete(context.Background(), slices.Values(ids), BulkOptions{})
+
+		for _, result := range results {
+			suite.NoError(result.Err, "BulkDelete() should not return an error.")
+		}
+	})
+}
+
+func TestBulkCancelled(t *testing.T) {
+	client := NewClient("http://127.0.0.1:0", "", 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := client.BulkDelete(ctx, slices.Values([]string{"a", "b", "c"}), BulkOptions{})
+
+	assert.Len(t, results, 1, "No requests should be pulled once the context is done.")
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, BulkCancelled, result.Status)
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
+
+func TestBulkCancelUnbounded(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	ids := func(yield func(string) bool) {
+		for yield("id") {
+		}
+	}
+	client := NewClient(server.URL, "key", time.Minute)
+
+	done := make(chan []BulkResult[DeleteResponse])
+	go func() { done <- client.BulkDelete(ctx, ids, BulkOptions{Workers: 2}) }()
+
+	select {
+	case results := <-done:
+		assert.NotEmpty(t, results)
+		for _, result := range results {
+			assert.ErrorIs(t, result.Err, context.Canceled, "In-flight requests should be cancelled with ctx.")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("BulkDelete() didn't return after ctx was cancelled")
+	}
+}
@@ -0,0 +1,240 @@
+package passwork
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cacheKindPassword = "password"
+	cacheKindFolder   = "folder"
+	cacheKindVault    = "vault"
+)
+
+type CacheOptions struct {
+	// Time to live per response type, a zero TTL disables caching of that type
+	PasswordTTL time.Duration
+	FolderTTL   time.Duration
+	VaultTTL    time.Duration
+
+	// MaxEntries bounds the cache, least recently used entries are evicted first.
+	// Defaults to 1000.
+	MaxEntries int
+}
+
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// WithCache enables a read-through cache for GetPassword, GetFolder and GetVault.
+// Entries are invalidated by changes made through this client, including the
+// item and folder counts of vaults and folders, and are kept encrypted in memory with a random per-cache key.
+func WithCache(opts CacheOptions) Option {
+	return func(c *Client) {
+		c.cache = newResponseCache(opts)
+	}
+}
+
+// Uncached returns a client sharing this client's configuration whose reads
+// bypass the cache, responses are neither taken from nor kept in the cache or
+// the offline snapshot. Writes still invalidate cached entries and the
+// snapshot still serves stale reads while the server is unreachable.
+func (c *Client) Uncached() *Client {
+	uncached := *c
+	uncached.cacheBypass = true
+	return &uncached
+}
+
+// CacheStats returns hit and miss counters, ok is false if no cache is configured
+func (c *Client) CacheStats() (stats CacheStats, ok bool) {
+	if c.cache == nil {
+		return stats, false
+	}
+	return c.cache.stats(), true
+}
+
+type responseCache struct {
+	mu      sync.Mutex
+	opts    CacheOptions
+	aead    cipher.AEAD
+	lru     *list.List
+	entries map[string]*list.Element
+	counts  CacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	expires time.Time
+	nonce   []byte
+	sealed  []byte
+}
+
+func newResponseCache(opts CacheOptions) *responseCache {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1000
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("passwork: cannot generate cache key: " + err.Error())
+	}
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+
+	return &responseCache{
+		opts:    opts,
+		aead:    aead,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (rc *responseCache) ttl(kind string) time.Duration {
+	switch kind {
+	case cacheKindPassword:
+		return rc.opts.PasswordTTL
+	case cacheKindFolder:
+		return rc.opts.FolderTTL
+	case cacheKindVault:
+		return rc.opts.VaultTTL
+	}
+	return 0
+}
+
+func (rc *responseCache) get(kind, id string, target any) bool {
+	if rc.ttl(kind) <= 0 {
+		return false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	element, ok := rc.entries[kind+"/"+id]
+	if !ok {
+		rc.counts.Misses++
+		return false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		rc.remove(element)
+		rc.counts.Misses++
+		return false
+	}
+
+	plaintext, err := rc.aead.Open(nil, entry.nonce, entry.sealed, []byte(entry.key))
+	if err != nil || gob.NewDecoder(bytes.NewReader(plaintext)).Decode(target) != nil {
+		rc.remove(element)
+		rc.counts.Misses++
+		return false
+	}
+	clear(plaintext)
+
+	rc.lru.MoveToFront(element)
+	rc.counts.Hits++
+	return true
+}
+
+func (rc *responseCache) set(kind, id string, value any) {
+	ttl := rc.ttl(kind)
+	if ttl <= 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return
+	}
+	plaintext := buf.Bytes()
+	entry := &cacheEntry{key: kind + "/" + id, expires: time.Now().Add(ttl), nonce: make([]byte, rc.aead.NonceSize())}
+	rand.Read(entry.nonce)
+	entry.sealed = rc.aead.Seal(nil, entry.nonce, plaintext, []byte(entry.key))
+	clear(plaintext)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if element, ok := rc.entries[entry.key]; ok {
+		rc.remove(element)
+	}
+	rc.entries[entry.key] = rc.lru.PushFront(entry)
+
+	for rc.lru.Len() > rc.opts.MaxEntries {
+		rc.remove(rc.lru.Back())
+		rc.counts.Evictions++
+	}
+}
+
+func (rc *responseCache) invalidate(kind, id string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if element, ok := rc.entries[kind+"/"+id]; ok {
+		rc.remove(element)
+	}
+}
+
+// invalidateKind drops every entry of a kind
+func (rc *responseCache) invalidateKind(kind string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for key, element := range rc.entries {
+		if strings.HasPrefix(key, kind+"/") {
+			rc.remove(element)
+		}
+	}
+}
+
+func (rc *responseCache) remove(element *list.Element) {
+	rc.lru.Remove(element)
+	delete(rc.entries, element.Value.(*cacheEntry).key)
+}
+
+func (rc *responseCache) stats() CacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stats := rc.counts
+	stats.Entries = rc.lru.Len()
+	return stats
+}
+
+// cacheLookup fills target from the cache unless the client bypasses it
+func (c *Client) cacheLookup(kind, id string, target any) bool {
+	if c.cache == nil || c.cacheBypass {
+		return false
+	}
+	return c.cache.get(kind, id, target)
+}
+
+func (c *Client) cacheStore(kind, id string, value any) {
+	if c.cache != nil && !c.cacheBypass {
+		c.cache.set(kind, id, value)
+	}
+}
+
+func (c *Client) cacheInvalidate(kind, id string) {
+	if c.cache != nil {
+		c.cache.invalidate(kind, id)
+	}
+}
+
+// cacheInvalidateKind is used when a change affects entries that can't be
+// told from the request, like the counts of the vault of a deleted item
+func (c *Client) cacheInvalidateKind(kinds ...string) {
+	if c.cache != nil {
+		for _, kind := range kinds {
+			c.cache.invalidateKind(kind)
+		}
+	}
+}
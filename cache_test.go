@@ -0,0 +1,170 @@
+package passwork
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCacheTestServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			gets.Add(1)
+			w.Write([]byte(`{"status":"success","data":{"id":"pw1","name":"entry","cryptedPassword":"c2VjcmV0LXZhbHVl"}}`))
+		case http.MethodPut:
+			w.Write([]byte(`{"status":"success","data":{"id":"pw1","name":"edited"}}`))
+		case http.MethodDelete:
+			w.Write([]byte(`{"status":"success","data":"passwordDeleted"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &gets
+}
+
+func TestCacheReadThrough(t *testing.T) {
+	server, gets := newCacheTestServer(t)
+	client := NewClient(server.URL, "key", time.Second, WithCache(CacheOptions{PasswordTTL: time.Minute}))
+
+	first, err := client.GetPassword("pw1")
+	require.NoError(t, err)
+	second, err := client.GetPassword("pw1")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, int32(1), gets.Load(), "The second read should be served from the cache.")
+
+	stats, ok := client.CacheStats()
+	assert.True(t, ok)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Entries: 1}, stats)
+
+	_, err = client.Uncached().GetPassword("pw1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), gets.Load(), "Uncached reads should bypass the cache.")
+}
+
+func TestUncachedDoesNotStore(t *testing.T) {
+	server, _ := newCacheTestServer(t)
+	snapshot := newTestOfflineSnapshot(t, filepath.Join(t.TempDir(), "snapshot"))
+	client := NewClient(server.URL, "key", time.Second, WithCache(CacheOptions{PasswordTTL: time.Minute}), WithOfflineSnapshot(snapshot))
+
+	_, err := client.Uncached().GetPassword("pw1")
+	require.NoError(t, err)
+
+	stats, _ := client.CacheStats()
+	assert.Zero(t, stats.Entries, "Uncached reads should not be cached.")
+	assert.Empty(t, snapshot.data.Items, "Uncached reads should not be kept in the snapshot.")
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	server, gets := newCacheTestServer(t)
+	client := NewClient(server.URL, "key", time.Second, WithCache(CacheOptions{PasswordTTL: time.Minute}))
+
+	client.GetPassword("pw1")
+	_, err := client.EditPassword("pw1", PasswordRequest{Name: "edited"})
+	require.NoError(t, err)
+	client.GetPassword("pw1")
+	assert.Equal(t, int32(2), gets.Load(), "EditPassword should invalidate the cached entry.")
+
+	_, err = client.DeletePassword("pw1")
+	require.NoError(t, err)
+	client.GetPassword("pw1")
+	assert.Equal(t, int32(3), gets.Load(), "DeletePassword should invalidate the cached entry.")
+}
+
+func TestCacheInvalidatesCounts(t *testing.T) {
+	var mu sync.Mutex
+	gets := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			gets[r.URL.Path]++
+		case http.MethodDelete:
+			w.Write([]byte(`{"status":"success","data":"deleted"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"id":"x"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", time.Second, WithCache(CacheOptions{VaultTTL: time.Minute, FolderTTL: time.Minute}))
+	read := func() {
+		client.GetVault("v1")
+		client.GetFolder("f1")
+	}
+
+	changes := map[string]func() error{
+		"AddPassword": func() error {
+			_, err := client.AddPassword(PasswordRequest{VaultId: "v1", FolderId: "f1"})
+			return err
+		},
+		"DeletePassword": func() error {
+			_, err := client.DeletePassword("pw1")
+			return err
+		},
+		"AddFolder": func() error {
+			_, err := client.AddFolder(FolderRequest{VaultId: "v1", ParentId: "f1"})
+			return err
+		},
+		"DeleteFolder": func() error {
+			_, err := client.DeleteFolder("f2")
+			return err
+		},
+		"UpdateVaultSettings": func() error {
+			_, err := client.UpdateVaultSettings("v1", VaultSettingsData{})
+			return err
+		},
+	}
+	for name, change := range changes {
+		read()
+		before := maps.Clone(gets)
+		require.NoError(t, change(), name)
+		read()
+		assert.Equal(t, before["/vaults/v1"]+1, gets["/vaults/v1"], "%s should invalidate the vault.", name)
+		if name != "UpdateVaultSettings" {
+			assert.Equal(t, before["/folders/f1"]+1, gets["/folders/f1"], "%s should invalidate the folder.", name)
+		}
+	}
+}
+
+func TestCacheExpiryAndEviction(t *testing.T) {
+	cache := newResponseCache(CacheOptions{PasswordTTL: 20 * time.Millisecond, MaxEntries: 2})
+	var response PasswordResponse
+
+	cache.set(cacheKindPassword, "a", PasswordResponse{Status: "success"})
+	cache.set(cacheKindPassword, "b", PasswordResponse{Status: "success"})
+	assert.True(t, cache.get(cacheKindPassword, "a", &response))
+	cache.set(cacheKindPassword, "c", PasswordResponse{Status: "success"})
+
+	assert.False(t, cache.get(cacheKindPassword, "b", &response), "The least recently used entry should be evicted.")
+	assert.True(t, cache.get(cacheKindPassword, "a", &response))
+	assert.Equal(t, uint64(1), cache.stats().Evictions)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, cache.get(cacheKindPassword, "a", &response), "Expired entries should not be served.")
+
+	// Types without TTL are not cached
+	cache.set(cacheKindFolder, "f", FolderResponse{Status: "success"})
+	assert.False(t, cache.get(cacheKindFolder, "f", &FolderResponse{}))
+}
+
+func TestCacheEncryptsEntries(t *testing.T) {
+	cache := newResponseCache(CacheOptions{PasswordTTL: time.Minute})
+
+	cache.set(cacheKindPassword, "a", PasswordResponse{Data: PasswordResponseData{CryptedPassword: NewSecret("plain-secret")}})
+
+	entry := cache.entries[cacheKindPassword+"/a"].Value.(*cacheEntry)
+	assert.False(t, bytes.Contains(entry.sealed, []byte("plain-secret")))
+}
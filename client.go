@@ -1,10 +1,11 @@
 package passwork
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -16,6 +17,23 @@ type Client struct {
 	apiKey       string
 	sessionToken string
 	HTTPClient   *http.Client
+	rateLimiter  *RateLimiter
+	cache        *responseCache
+	cacheBypass  bool
+	offline      *OfflineSnapshot
+	logger       *slog.Logger
+	instrument   Instrumentation
+	interceptors []Interceptor
+}
+
+// Option configures optional behaviour of a Client
+type Option func(*Client)
+
+// WithRateLimit limits the client to rps requests per second with bursts of burst requests
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		c.rateLimiter = NewRateLimiter(rps, burst)
+	}
 }
 
 type LoginResponse struct {
@@ -39,11 +57,12 @@ type LoginResponseData struct {
 }
 
 type User struct {
+	Id    string
 	Name  string
 	Email string
 }
 
-func NewClient(baseURL, apiKey string, timeout time.Duration) *Client {
+func NewClient(baseURL, apiKey string, timeout time.Duration, opts ...Option) *Client {
 	client := Client{
 		BaseURL:      baseURL,
 		apiKey:       apiKey,
@@ -53,9 +72,21 @@ func NewClient(baseURL, apiKey string, timeout time.Duration) *Client {
 		},
 	}
 
+	for _, opt := range opts {
+		opt(&client)
+	}
+
 	return &client
 }
 
+// RateLimitState returns the state of the rate limiter, ok is false if none is configured
+func (c *Client) RateLimitState() (state RateLimitState, ok bool) {
+	if c.rateLimiter == nil {
+		return state, false
+	}
+	return c.rateLimiter.State(), true
+}
+
 // Perform Login Request and set session Token in struct
 // For API v1, the API key is used directly as the bearer token
 func (c *Client) Login() error {
@@ -66,30 +97,53 @@ func (c *Client) Login() error {
 }
 
 func (c *Client) Logout() error {
-	url := fmt.Sprintf("%s/auth/logout", c.BaseURL)
+	call := &Call{
+		Operation: "Logout",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/auth/logout", c.BaseURL),
+	}
+	_, err := invoke(context.Background(), c, call, func(ctx context.Context) (LogoutResponse, error) {
+		return c.logout(ctx, call)
+	})
+	return err
+}
 
-	response, _, err := c.sendRequest(http.MethodPost, url, nil)
+func (c *Client) logout(ctx context.Context, call *Call) (LogoutResponse, error) {
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
 	if err != nil {
-		return err
+		return LogoutResponse{}, err
 	}
 
 	responseObject, err := utils.ParseJSONResponse[LogoutResponse](response)
 	if err != nil {
-		return err
+		return responseObject, err
 	}
 
 	if responseObject.Status == "success" && responseObject.Data == "loggedOut" {
-		return nil
+		return responseObject, nil
 	}
 
-	return fmt.Errorf("logout failed, status: %s", responseObject.Status)
+	return responseObject, fmt.Errorf("logout failed, status: %s", responseObject.Status)
 }
 
 // Sends HTTP request to URL with method and body
 // Returns response body
 func (c *Client) sendRequest(method string, url string, body io.Reader) ([]byte, int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	return c.sendRequestContext(context.Background(), method, url, body)
+}
+
+// Sends HTTP request to URL with method and body, bound to the given context
+// Returns response body
+func (c *Client) sendRequestContext(ctx context.Context, method string, url string, body io.Reader) ([]byte, int, error) {
+	// Keep the body around for the debug log
+	var requestBody []byte
+	if body != nil && c.log().Enabled(ctx, slog.LevelDebug) {
+		var err error
+		if requestBody, err = io.ReadAll(body); err != nil {
+			return nil, 0, err
+		}
+		body = bytes.NewReader(requestBody)
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
@@ -106,10 +160,40 @@ func (c *Client) sendRequest(method string, url string, body io.Reader) ([]byte,
 		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	}
 
+	for name, values := range callHeader(ctx) {
+		req.Header[name] = values
+	}
+
+	if c.rateLimiter != nil {
+		waitStart := time.Now()
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, 0, err
+		}
+		if waited := time.Since(waitStart); waited > time.Millisecond {
+			c.log().LogAttrs(ctx, slog.LevelDebug, "passwork rate limited",
+				slog.String("method", method), slog.String("path", req.URL.Path), slog.Duration("waited", waited))
+		}
+	}
+
+	// Time spent waiting for the rate limiter doesn't count towards the timeout
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	endAttempt := c.startAttempt(req)
+	c.logRequest(ctx, req, requestBody)
+	start := time.Now()
+
 	// Execute HTTP request
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		log.Printf("HTTP Request failed: %v", err)
+		endAttempt(0, err)
+		c.log().LogAttrs(ctx, slog.LevelWarn, "passwork request failed",
+			slog.String("method", method), slog.String("path", req.URL.Path),
+			slog.Duration("duration", time.Since(start)), slog.Any("error", err))
+		if c.offline != nil && isUnreachable(0, err) {
+			return nil, 0, fmt.Errorf("%w: %v", ErrOffline, err)
+		}
 		if resp != nil {
 			return nil, resp.StatusCode, err
 		}
@@ -117,11 +201,24 @@ func (c *Client) sendRequest(method string, url string, body io.Reader) ([]byte,
 	}
 	defer resp.Body.Close()
 
+	if c.rateLimiter != nil {
+		c.rateLimiter.Observe(resp)
+	}
+
 	// Convert Body into byte stream
 	responseData, err := io.ReadAll(resp.Body)
+	endAttempt(resp.StatusCode, err)
 	if err != nil {
 		return nil, resp.StatusCode, err
 	}
 
+	c.log().LogAttrs(ctx, slog.LevelDebug, "passwork response",
+		slog.String("method", method), slog.String("path", req.URL.Path), slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", time.Since(start)), slog.Int("size", len(responseData)))
+
+	if c.offline != nil && isUnreachable(resp.StatusCode, nil) {
+		return nil, resp.StatusCode, fmt.Errorf("%w: %s", ErrOffline, resp.Status)
+	}
+
 	return responseData, resp.StatusCode, nil
 }
@@ -20,6 +20,7 @@ type PassworkTestSuite struct {
 	FolderName   string
 	PasswordId   string
 	PasswordName string
+	InboxUserId  string
 	client       *Client
 }
 
@@ -58,6 +59,7 @@ func (suite *PassworkTestSuite) SetupSuite() {
 	suite.ApiKey = os.Getenv("PASSWORK_API_KEY")
 	suite.Host = os.Getenv("PASSWORK_HOST")
 	suite.VaultId = os.Getenv("PASSWORK_VAULT_ID")
+	suite.InboxUserId = os.Getenv("PASSWORK_INBOX_USER_ID")
 
 	suite.client = NewClient(suite.Host, suite.ApiKey, time.Second*30)
 	err := suite.client.Login()
@@ -0,0 +1,320 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Moved    ChangeType = "moved"
+	Modified ChangeType = "modified"
+)
+
+type Change struct {
+	Type    ChangeType
+	Kind    string // folder, item
+	Path    string // Path in b, or in a for removed objects
+	OldPath string // Path in a for moved objects
+	Fields  []FieldChange
+}
+
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+
+	// Secret is set for secret fields, Old and New are always empty for them
+	Secret bool
+}
+
+type Report struct {
+	Changes []Change
+}
+
+// Equal reports whether both sources match
+func (r Report) Equal() bool {
+	return len(r.Changes) == 0
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	for _, change := range r.Changes {
+		switch change.Type {
+		case Moved:
+			fmt.Fprintf(&b, "%s %s %s -> %s\n", change.Type, change.Kind, change.OldPath, change.Path)
+		default:
+			fmt.Fprintf(&b, "%s %s %s\n", change.Type, change.Kind, change.Path)
+		}
+		for _, field := range change.Fields {
+			if field.Secret {
+				fmt.Fprintf(&b, "  %s: changed\n", field.Field)
+			} else {
+				fmt.Fprintf(&b, "  %s: %q -> %q\n", field.Field, field.Old, field.New)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Diff compares two sources. Objects are matched by ID first, then by folder
+// path and name, and finally by name alone when it is unique on both sides,
+// which detects moves between instances where IDs differ.
+func Diff(ctx context.Context, a, b Source) (Report, error) {
+	before, err := a.Snapshot(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	after, err := b.Snapshot(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	return Compare(before, after), nil
+}
+
+// Compare diffs two snapshots. Objects whose parent folder changed are moved,
+// renames in place are reported as a modified name.
+func Compare(a, b passwork.ArchiveManifest) Report {
+	var report Report
+
+	folderPathsA := folderPaths(a)
+	folderPathsB := folderPaths(b)
+
+	// Folders
+	folderId := func(f passwork.ArchiveFolder) string { return f.Id }
+	folderPath := func(f passwork.ArchiveFolder) string { return strings.Join(f.Path, "/") }
+	folderPairs, removedFolders, addedFolders := match(a.Folders, b.Folders,
+		[][2]func(passwork.ArchiveFolder) string{{folderId, folderId}, {folderPath, folderPath}},
+		func(f passwork.ArchiveFolder) string { return f.Name })
+	for _, folder := range removedFolders {
+		report.Changes = append(report.Changes, Change{Type: Removed, Kind: "folder", Path: strings.Join(folder.Path, "/")})
+	}
+	for _, folder := range addedFolders {
+		report.Changes = append(report.Changes, Change{Type: Added, Kind: "folder", Path: strings.Join(folder.Path, "/")})
+	}
+
+	// Objects moved when their parent folder changed. Parents are compared as
+	// matched folders, so renaming or moving a folder doesn't move its contents.
+	counterparts := make(map[string]string, len(folderPairs))
+	for _, pair := range folderPairs {
+		counterparts[pair[0].Id] = pair[1].Id
+	}
+	moved := func(parentA, parentB string) bool {
+		if _, ok := folderPathsA[parentA]; !ok {
+			parentA = ""
+		}
+		if _, ok := folderPathsB[parentB]; !ok {
+			parentB = ""
+		}
+		if parentA == "" || parentB == "" {
+			return parentA != parentB
+		}
+		counterpart, ok := counterparts[parentA]
+		return !ok || counterpart != parentB
+	}
+
+	for _, pair := range folderPairs {
+		oldPath, newPath := strings.Join(pair[0].Path, "/"), strings.Join(pair[1].Path, "/")
+		var fields []FieldChange
+		if pair[0].Name != pair[1].Name {
+			fields = append(fields, FieldChange{Field: "name", Old: pair[0].Name, New: pair[1].Name})
+		}
+		if moved(pair[0].ParentId, pair[1].ParentId) {
+			report.Changes = append(report.Changes, Change{Type: Moved, Kind: "folder", Path: newPath, OldPath: oldPath, Fields: fields})
+		} else if len(fields) > 0 {
+			report.Changes = append(report.Changes, Change{Type: Modified, Kind: "folder", Path: newPath, Fields: fields})
+		}
+	}
+
+	// Items
+	itemPath := func(paths map[string]string) func(passwork.ArchiveItem) string {
+		return func(item passwork.ArchiveItem) string {
+			if folder := paths[item.FolderId]; folder != "" {
+				return folder + "/" + item.Name
+			}
+			return item.Name
+		}
+	}
+	pathA, pathB := itemPath(folderPathsA), itemPath(folderPathsB)
+
+	itemId := func(i passwork.ArchiveItem) string { return i.Id }
+	itemPairs, removedItems, addedItems := match(a.Items, b.Items,
+		[][2]func(passwork.ArchiveItem) string{{itemId, itemId}, {pathA, pathB}},
+		func(i passwork.ArchiveItem) string { return i.Name })
+
+	for _, item := range removedItems {
+		report.Changes = append(report.Changes, Change{Type: Removed, Kind: "item", Path: pathA(item)})
+	}
+	for _, item := range addedItems {
+		report.Changes = append(report.Changes, Change{Type: Added, Kind: "item", Path: pathB(item)})
+	}
+	for _, pair := range itemPairs {
+		oldPath, newPath := pathA(pair[0]), pathB(pair[1])
+		fields := compareItems(pair[0], pair[1])
+		if moved(pair[0].FolderId, pair[1].FolderId) {
+			report.Changes = append(report.Changes, Change{Type: Moved, Kind: "item", Path: newPath, OldPath: oldPath, Fields: fields})
+		} else if len(fields) > 0 {
+			report.Changes = append(report.Changes, Change{Type: Modified, Kind: "item", Path: newPath, Fields: fields})
+		}
+	}
+
+	slices.SortStableFunc(report.Changes, func(x, y Change) int {
+		if x.Kind != y.Kind {
+			return strings.Compare(x.Kind, y.Kind)
+		}
+		return strings.Compare(x.Path, y.Path)
+	})
+
+	return report
+}
+
+func folderPaths(manifest passwork.ArchiveManifest) map[string]string {
+	paths := make(map[string]string, len(manifest.Folders))
+	for _, folder := range manifest.Folders {
+		paths[folder.Id] = strings.Join(folder.Path, "/")
+	}
+	return paths
+}
+
+// match pairs objects of a and b by each stage of keys in turn, then by names
+// that are unique among the remaining objects
+func match[T any](a, b []T, stages [][2]func(T) string, name func(T) string) ([][2]T, []T, []T) {
+	var pairs [][2]T
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+
+	for _, keys := range stages {
+		index := make(map[string]int)
+		for j, item := range b {
+			if k := keys[1](item); !matchedB[j] && k != "" {
+				index[k] = j
+			}
+		}
+		for i, item := range a {
+			k := keys[0](item)
+			if matchedA[i] || k == "" {
+				continue
+			}
+			if j, ok := index[k]; ok && !matchedB[j] {
+				pairs = append(pairs, [2]T{item, b[j]})
+				matchedA[i], matchedB[j] = true, true
+			}
+		}
+	}
+
+	// Names that are unique among the unmatched objects of both sides
+	count := func(items []T, matched []bool) map[string]int {
+		counts := make(map[string]int)
+		for i, item := range items {
+			if !matched[i] {
+				counts[name(item)]++
+			}
+		}
+		return counts
+	}
+	countA, countB := count(a, matchedA), count(b, matchedB)
+	for i, item := range a {
+		if matchedA[i] || countA[name(item)] != 1 || countB[name(item)] != 1 {
+			continue
+		}
+		for j, other := range b {
+			if !matchedB[j] && name(other) == name(item) {
+				pairs = append(pairs, [2]T{item, other})
+				matchedA[i], matchedB[j] = true, true
+				break
+			}
+		}
+	}
+
+	var removed, added []T
+	for i, item := range a {
+		if !matchedA[i] {
+			removed = append(removed, item)
+		}
+	}
+	for j, item := range b {
+		if !matchedB[j] {
+			added = append(added, item)
+		}
+	}
+
+	return pairs, removed, added
+}
+
+func compareItems(a, b passwork.ArchiveItem) []FieldChange {
+	var changes []FieldChange
+	field := func(name, old, new string) {
+		if old != new {
+			changes = append(changes, FieldChange{Field: name, Old: old, New: new})
+		}
+	}
+
+	field("name", a.Name, b.Name)
+	field("login", a.Login, b.Login)
+	if !a.Password.Equal(b.Password) {
+		changes = append(changes, FieldChange{Field: "password", Secret: true})
+	}
+	field("url", a.Url, b.Url)
+	field("description", a.Description, b.Description)
+	field("color", strconv.Itoa(a.Color), strconv.Itoa(b.Color))
+
+	tagsA, tagsB := slices.Sorted(slices.Values(a.Tags)), slices.Sorted(slices.Values(b.Tags))
+	field("tags", strings.Join(tagsA, ","), strings.Join(tagsB, ","))
+
+	customA, customB := customFields(a.Custom), customFields(b.Custom)
+	for _, name := range slices.Sorted(maps.Keys(mergeKeys(customA, customB))) {
+		old, okA := customA[name]
+		new, okB := customB[name]
+		if old.Equal(new) {
+			continue
+		}
+		// Only text fields are shown, totp seeds and unknown types are secret
+		if (okA && old.Type != "text") || (okB && new.Type != "text") {
+			changes = append(changes, FieldChange{Field: "custom." + name, Secret: true})
+		} else {
+			changes = append(changes, FieldChange{Field: "custom." + name, Old: old.Value.Reveal(), New: new.Value.Reveal()})
+		}
+	}
+
+	attachmentsA, attachmentsB := attachmentNames(a.Attachments), attachmentNames(b.Attachments)
+	field("attachments", strings.Join(attachmentsA, ","), strings.Join(attachmentsB, ","))
+
+	return changes
+}
+
+func customFields(fields []passwork.PasswordCustomData) map[string]passwork.PasswordCustomData {
+	result := make(map[string]passwork.PasswordCustomData, len(fields))
+	for _, field := range fields {
+		result[field.Name] = field
+	}
+	return result
+}
+
+func mergeKeys[V any](a, b map[string]V) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
+
+func attachmentNames(attachments []passwork.ArchiveAttachment) []string {
+	names := make([]string, len(attachments))
+	for i, attachment := range attachments {
+		names[i] = attachment.Name
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+	"github.com/treasure33/passwork-client-go/internal/archive"
+)
+
+func snapshot() passwork.ArchiveManifest {
+	return passwork.ArchiveManifest{
+		Folders: []passwork.ArchiveFolder{
+			{Id: "f1", Name: "infra", Path: []string{"infra"}},
+			{Id: "f2", ParentId: "f1", Name: "db", Path: []string{"infra", "db"}},
+		},
+		Items: []passwork.ArchiveItem{
+			{Id: "i1", FolderId: "f2", Name: "postgres", Login: "admin", Password: passwork.NewSecret("pw"), Tags: []string{"a", "b"}},
+			{Id: "i2", FolderId: "f1", Name: "api", Password: passwork.NewSecret("token"), Custom: []passwork.PasswordCustomData{{Name: "key", Value: passwork.NewSecret("k"), Type: "password"}}},
+			{Id: "i3", Name: "old"},
+		},
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	report := Compare(snapshot(), snapshot())
+
+	assert.True(t, report.Equal())
+}
+
+func TestCompareSameInstance(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.Items[0].Password = passwork.NewSecret("n3w-secret")
+	b.Items[0].Login = "root"
+	b.Items[0].Tags = []string{"b", "a"}
+	b.Items[1].FolderId = ""
+	b.Items[1].Custom[0].Value = passwork.NewSecret("k2")
+	b.Items = b.Items[:2]
+	b.Items = append(b.Items, passwork.ArchiveItem{Id: "i4", FolderId: "f1", Name: "new"})
+
+	report := Compare(a, b)
+
+	require.Len(t, report.Changes, 4)
+	assert.Equal(t, Change{Type: Moved, Kind: "item", Path: "api", OldPath: "infra/api", Fields: []FieldChange{{Field: "custom.key", Secret: true}}}, report.Changes[0])
+	assert.Equal(t, Change{
+		Type: Modified,
+		Kind: "item",
+		Path: "infra/db/postgres",
+		Fields: []FieldChange{
+			{Field: "login", Old: "admin", New: "root"},
+			{Field: "password", Secret: true},
+		},
+	}, report.Changes[1])
+	assert.Equal(t, Change{Type: Added, Kind: "item", Path: "infra/new"}, report.Changes[2])
+	assert.Equal(t, Change{Type: Removed, Kind: "item", Path: "old"}, report.Changes[3])
+
+	text := report.String()
+	assert.NotContains(t, text, "n3w-secret", "Secret values must never be printed.")
+	assert.NotContains(t, text, "k2", "Secret values must never be printed.")
+}
+
+func TestCompareAcrossInstances(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	// Different IDs everywhere
+	b.Folders[0].Id, b.Folders[1].Id, b.Folders[1].ParentId = "x1", "x2", "x1"
+	b.Items[0].Id, b.Items[0].FolderId = "y1", "x2"
+	b.Items[1].Id, b.Items[1].FolderId = "y2", "x2"
+	b.Items[2].Id = "y3"
+
+	report := Compare(a, b)
+
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, Change{Type: Moved, Kind: "item", Path: "infra/db/api", OldPath: "infra/api"}, report.Changes[0])
+}
+
+func TestCompareFolders(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.Folders[1].Path = []string{"db"}
+	b.Folders[1].ParentId = ""
+	b.Folders = append(b.Folders, passwork.ArchiveFolder{Id: "f3", Name: "empty", Path: []string{"empty"}})
+
+	report := Compare(a, b)
+
+	assert.Equal(t, []Change{
+		{Type: Moved, Kind: "folder", Path: "db", OldPath: "infra/db"},
+		{Type: Added, Kind: "folder", Path: "empty"},
+	}, report.Changes, "Items should move with their folder.")
+}
+
+func TestCompareRenames(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.Folders[0].Name, b.Folders[0].Path = "ops", []string{"ops"}
+	b.Folders[1].Path = []string{"ops", "db"}
+	b.Items[0].Name = "postgres-main"
+
+	report := Compare(a, b)
+
+	assert.Equal(t, []Change{
+		{Type: Modified, Kind: "folder", Path: "ops", Fields: []FieldChange{{Field: "name", Old: "infra", New: "ops"}}},
+		{Type: Modified, Kind: "item", Path: "ops/db/postgres-main", Fields: []FieldChange{{Field: "name", Old: "postgres", New: "postgres-main"}}},
+	}, report.Changes, "Renames in place should not be reported as moves.")
+}
+
+func TestCompareItemsCustomSecrets(t *testing.T) {
+	custom := func(fields ...passwork.PasswordCustomData) passwork.ArchiveItem {
+		return passwork.ArchiveItem{Custom: fields}
+	}
+	text := passwork.PasswordCustomData{Name: "env", Value: passwork.NewSecret("prod"), Type: "text"}
+	totp := passwork.PasswordCustomData{Name: "otp", Value: passwork.NewSecret("JBSWY3DP"), Type: "totp"}
+
+	assert.Equal(t, []FieldChange{{Field: "custom.env", New: "prod"}}, compareItems(custom(), custom(text)))
+	assert.Equal(t, []FieldChange{{Field: "custom.otp", Secret: true}}, compareItems(custom(totp), custom()))
+
+	untyped := text
+	untyped.Type = ""
+	assert.Equal(t, []FieldChange{{Field: "custom.env", Secret: true}}, compareItems(custom(untyped), custom(text)))
+}
+
+func TestDiffArchive(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := archive.NewWriter(&buf, "secret", 1000)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteManifest(snapshot()))
+	require.NoError(t, w.Close())
+
+	report, err := Diff(context.Background(), Archive(&buf, "secret"), Manifest(snapshot()))
+
+	require.NoError(t, err)
+	assert.True(t, report.Equal())
+}
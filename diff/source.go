@@ -0,0 +1,61 @@
+// Package diff compares the structure and content of two vaults or snapshots.
+package diff
+
+import (
+	"context"
+	"io"
+
+	passwork "github.com/treasure33/passwork-client-go"
+	"github.com/treasure33/passwork-client-go/internal/archive"
+)
+
+// Source provides a snapshot of a vault to compare
+type Source interface {
+	Snapshot(ctx context.Context) (passwork.ArchiveManifest, error)
+}
+
+type vaultSource struct {
+	client  *passwork.Client
+	vaultId string
+}
+
+// Vault is a live vault as source
+func Vault(client *passwork.Client, vaultId string) Source {
+	return vaultSource{client: client, vaultId: vaultId}
+}
+
+func (s vaultSource) Snapshot(ctx context.Context) (passwork.ArchiveManifest, error) {
+	return s.client.SnapshotVault(ctx, s.vaultId)
+}
+
+type archiveSource struct {
+	r          io.Reader
+	passphrase string
+}
+
+// Archive is an archive written by ExportVault as source
+func Archive(r io.Reader, passphrase string) Source {
+	return archiveSource{r: r, passphrase: passphrase}
+}
+
+func (s archiveSource) Snapshot(ctx context.Context) (passwork.ArchiveManifest, error) {
+	var manifest passwork.ArchiveManifest
+
+	reader, err := archive.Read(s.r, s.passphrase)
+	if err != nil {
+		return manifest, err
+	}
+	err = reader.DecodeManifest(&manifest)
+	return manifest, err
+}
+
+type manifestSource passwork.ArchiveManifest
+
+// Manifest is an in-memory snapshot as source
+func Manifest(manifest passwork.ArchiveManifest) Source {
+	return manifestSource(manifest)
+}
+
+func (s manifestSource) Snapshot(ctx context.Context) (passwork.ArchiveManifest, error) {
+	return passwork.ArchiveManifest(s), nil
+}
@@ -0,0 +1,11 @@
+package passwork
+
+// APIError is returned when the API answers with a status other than success.
+// Its message is the API code, e.g. passwordNull or accessDenied.
+type APIError struct {
+	Code string
+}
+
+func (e *APIError) Error() string {
+	return e.Code
+}
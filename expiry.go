@@ -0,0 +1,119 @@
+package passwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExpiryField is the custom field holding the expiry date of an item
+const ExpiryField = "Expires"
+
+const expiryLayout = "2006-01-02"
+
+// Matches the "Expires: 2025-01-31" convention in descriptions
+var descriptionExpiry = regexp.MustCompile(`(?im)^\s*expires?:\s*(\S+)\s*$`)
+
+// Expiry returns when the item expires. The ExpiryField custom field takes
+// precedence over an "Expires: <date>" line in the description.
+func (p PasswordResponseData) Expiry() (time.Time, bool) {
+	for _, custom := range p.Custom {
+		if strings.EqualFold(custom.Name, ExpiryField) {
+			return parseExpiry(custom.Value.Reveal())
+		}
+	}
+	if match := descriptionExpiry.FindStringSubmatch(p.Description); match != nil {
+		return parseExpiry(match[1])
+	}
+	return time.Time{}, false
+}
+
+// SetExpiry stores the expiry date in the ExpiryField custom field, a zero time
+// removes it. The last instant of a UTC day is stored as a date.
+func (r *PasswordRequest) SetExpiry(expiresAt time.Time) {
+	r.Custom = slices.DeleteFunc(slices.Clone(r.Custom), func(custom PasswordCustomData) bool {
+		return strings.EqualFold(custom.Name, ExpiryField)
+	})
+	if expiresAt.IsZero() {
+		return
+	}
+
+	value := expiresAt.UTC().Format(time.RFC3339)
+	if next := expiresAt.Add(time.Nanosecond); next.Equal(next.Truncate(24 * time.Hour)) {
+		value = expiresAt.UTC().Format(expiryLayout)
+	}
+	r.Custom = append(r.Custom, PasswordCustomData{Name: ExpiryField, Value: NewSecret(value), Type: "text"})
+}
+
+// parseExpiry parses a timestamp or a date, which expires at the end of the day in UTC
+func parseExpiry(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(expiryLayout, value); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+	}
+	return time.Time{}, false
+}
+
+type ExpiringItem struct {
+	Item      PasswordResponseData // Without CryptedPassword and CryptedKey
+	ExpiresAt time.Time
+	Expired   bool
+}
+
+// ExpiringItems Walk all vaults and return items expiring within the given duration
+// Items that already expired are included, the result is sorted by expiry.
+// Vaults and items that can't be read are skipped, their errors are joined
+// into the returned error next to the items that were found.
+func (c *Client) ExpiringItems(ctx context.Context, within time.Duration) ([]ExpiringItem, error) {
+	vaults, err := c.ListVaultsContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("expiry: list vaults: %w", err)
+	}
+
+	now := time.Now()
+	deadline := now.Add(within)
+
+	var result []ExpiringItem
+	var errs []error
+	for _, vault := range vaults.Data {
+		items, err := c.SearchPasswordContext(ctx, PasswordSearchRequest{VaultId: vault.Id})
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("expiry: list items of vault %s: %w", vault.Id, err))
+			continue
+		}
+
+		for _, summary := range items.Data {
+			// Search results may lack custom fields, fetch every item in full
+			item, err := c.GetPasswordContext(ctx, summary.Id)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("expiry: get item %s: %w", summary.Id, err))
+				continue
+			}
+
+			expiresAt, ok := item.Data.Expiry()
+			if !ok || expiresAt.After(deadline) {
+				continue
+			}
+
+			data := item.Data
+			data.CryptedPassword, data.CryptedKey = Secret{}, Secret{}
+			result = append(result, ExpiringItem{Item: data, ExpiresAt: expiresAt, Expired: !expiresAt.After(now)})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b ExpiringItem) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	return result, errors.Join(errs...)
+}
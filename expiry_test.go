@@ -0,0 +1,108 @@
+package passwork
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiry(t *testing.T) {
+	item := PasswordResponseData{Custom: []PasswordCustomData{{Name: "expires", Value: NewSecret("2025-01-31")}}}
+	expiresAt, ok := item.Expiry()
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC), expiresAt, "A date should expire at the end of the day.")
+
+	item = PasswordResponseData{Description: "API token for CI\nExpires: 2025-06-01T12:00:00Z\n"}
+	expiresAt, ok = item.Expiry()
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), expiresAt)
+
+	_, ok = PasswordResponseData{Description: "expires soon"}.Expiry()
+	assert.False(t, ok)
+
+	request := PasswordRequest{Custom: []PasswordCustomData{{Name: "env", Value: NewSecret("prod")}, {Name: ExpiryField, Value: NewSecret("2020-01-01")}}}
+	request.SetExpiry(time.Date(2026, 3, 1, 23, 59, 59, 999999999, time.UTC))
+	assert.Equal(t, []PasswordCustomData{{Name: "env", Value: NewSecret("prod")}, {Name: ExpiryField, Value: NewSecret("2026-03-01"), Type: "text"}}, request.Custom)
+
+	request.SetExpiry(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2026-03-01T00:00:00Z", request.Custom[1].Value.Reveal())
+
+	request.SetExpiry(time.Time{})
+	assert.Equal(t, []PasswordCustomData{{Name: "env", Value: NewSecret("prod")}}, request.Custom)
+}
+
+func TestExpiringItems(t *testing.T) {
+	now := time.Now().UTC()
+	items := map[string]PasswordResponseData{
+		"expired": {Id: "expired", VaultId: "v1", CryptedPassword: NewSecret("c2VjcmV0"),
+			Custom: []PasswordCustomData{{Name: ExpiryField, Value: NewSecret(now.Add(-time.Hour).Format(time.RFC3339))}}},
+		"soon": {Id: "soon", VaultId: "v2",
+			Description: "Expires: " + now.Add(48*time.Hour).Format(time.RFC3339)},
+		"later": {Id: "later", VaultId: "v1",
+			Custom: []PasswordCustomData{{Name: ExpiryField, Value: NewSecret(now.Add(90 * 24 * time.Hour).Format("2006-01-02"))}}},
+		"never": {Id: "never", VaultId: "v2"},
+		"today": {Id: "today", VaultId: "v2",
+			Custom: []PasswordCustomData{{Name: ExpiryField, Value: NewSecret(now.Format("2006-01-02"))}}},
+		"broken": {Id: "broken", VaultId: "v1"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/vaults":
+			w.Write([]byte(`{"status":"success","data":[{"id":"v1"},{"id":"v2"}]}`))
+		case r.URL.Path == "/items/search":
+			var summaries []PasswordResponseData
+			for _, item := range items {
+				if item.VaultId == r.URL.Query().Get("vaultId") {
+					summaries = append(summaries, PasswordResponseData{Id: item.Id, VaultId: item.VaultId})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": summaries})
+		case r.URL.Path == "/items/broken":
+			w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+		default:
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": revealed(items[strings.TrimPrefix(r.URL.Path, "/items/")])})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", time.Second)
+	expiring, err := client.ExpiringItems(context.Background(), 7*24*time.Hour)
+	assert.EqualError(t, err, "expiry: get item broken: accessDenied", "Failed items should be reported without stopping the walk.")
+	require.Len(t, expiring, 3)
+
+	assert.Equal(t, "expired", expiring[0].Item.Id)
+	assert.True(t, expiring[0].Expired)
+	assert.True(t, expiring[0].Item.CryptedPassword.IsEmpty())
+	assert.Equal(t, "today", expiring[1].Item.Id)
+	assert.False(t, expiring[1].Expired, "A date should only expire at the end of the day.")
+	assert.Equal(t, "soon", expiring[2].Item.Id)
+	assert.False(t, expiring[2].Expired)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.ExpiringItems(ctx, time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// revealed encodes an item the way the API sends it, with secrets in clear
+func revealed(item PasswordResponseData) map[string]any {
+	data, _ := json.Marshal(item)
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+
+	fields["CryptedPassword"] = item.CryptedPassword.Reveal()
+	custom := make([]map[string]string, len(item.Custom))
+	for i, c := range item.Custom {
+		custom[i] = map[string]string{"name": c.Name, "value": c.Value.Reveal(), "type": c.Type}
+	}
+	fields["Custom"] = custom
+	return fields
+}
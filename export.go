@@ -0,0 +1,195 @@
+package passwork
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/treasure33/passwork-client-go/internal/archive"
+)
+
+// ExportVault Write all folders, items and attachments of a vault into an encrypted archive
+// Passwords are decrypted and stored in plain text inside the archive, which is
+// encrypted as a whole with a key derived from opts.Passphrase.
+func (c *Client) ExportVault(ctx context.Context, vaultId string, w io.Writer, opts ExportOptions) error {
+	if opts.Passphrase == "" {
+		return errors.New("export: passphrase must not be empty")
+	}
+
+	manifest, attachments, err := c.snapshotVault(ctx, vaultId, !opts.SkipAttachments)
+	if err != nil {
+		return err
+	}
+
+	aw, err := archive.NewWriter(w, opts.Passphrase, opts.Iterations)
+	if err != nil {
+		return err
+	}
+	if err := aw.WriteManifest(manifest); err != nil {
+		return err
+	}
+	for _, item := range manifest.Items {
+		for _, attachment := range item.Attachments {
+			if err := aw.WriteFile(attachment.File, attachments[attachment.File]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return aw.Close()
+}
+
+// SnapshotVault Collect all folders and items of a vault with decrypted passwords
+// The result has the same shape as the manifest of an exported archive.
+func (c *Client) SnapshotVault(ctx context.Context, vaultId string) (ArchiveManifest, error) {
+	manifest, _, err := c.snapshotVault(ctx, vaultId, false)
+	return manifest, err
+}
+
+// SnapshotVaultWithAttachments Collect all folders and items of a vault including attachment blobs
+// The blobs are keyed by ArchiveAttachment.File.
+func (c *Client) SnapshotVaultWithAttachments(ctx context.Context, vaultId string) (ArchiveManifest, map[string][]byte, error) {
+	return c.snapshotVault(ctx, vaultId, true)
+}
+
+// snapshotVault walks a vault and collects its folders and items with decrypted
+// secrets, and optionally the attachment blobs keyed by their archive path
+func (c *Client) snapshotVault(ctx context.Context, vaultId string, withAttachments bool) (ArchiveManifest, map[string][]byte, error) {
+	manifest := ArchiveManifest{
+		Version:    ArchiveVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	attachments := make(map[string][]byte)
+
+	vault, err := c.GetVaultContext(ctx, vaultId)
+	if err != nil {
+		return manifest, nil, fmt.Errorf("export: get vault: %w", err)
+	}
+	manifest.Vault = ArchiveVault{Id: vault.Data.Id, Name: vault.Data.Name}
+
+	folders, err := c.searchFolderContext(ctx, FolderSearchRequest{VaultId: vaultId})
+	if err != nil {
+		return manifest, nil, fmt.Errorf("export: list folders: %w", err)
+	}
+	manifest.Folders = archiveFolders(folders.Data)
+
+	items, err := c.SearchPasswordContext(ctx, PasswordSearchRequest{VaultId: vaultId})
+	if err != nil {
+		return manifest, nil, fmt.Errorf("export: list items: %w", err)
+	}
+
+	for _, summary := range items.Data {
+		if err := ctx.Err(); err != nil {
+			return manifest, nil, err
+		}
+
+		// Search results don't carry secrets, fetch every item in full
+		password, err := c.GetPasswordContext(ctx, summary.Id)
+		if err != nil {
+			return manifest, nil, fmt.Errorf("export: get item %s: %w", summary.Id, err)
+		}
+
+		item, err := archiveItem(password.Data)
+		if err != nil {
+			return manifest, nil, fmt.Errorf("export: item %s: %w", summary.Id, err)
+		}
+
+		if withAttachments {
+			for i, attachment := range item.Attachments {
+				result, err := c.GetAttachmentContext(ctx, item.Id, attachment.Id)
+				if err != nil {
+					return manifest, nil, fmt.Errorf("export: get attachment %s of item %s: %w", attachment.Id, item.Id, err)
+				}
+				data, err := base64.StdEncoding.DecodeString(result.Data.EncryptedData)
+				if err != nil {
+					return manifest, nil, fmt.Errorf("export: decode attachment %s of item %s: %w", attachment.Id, item.Id, err)
+				}
+				item.Attachments[i].File = fmt.Sprintf("attachments/%s/%s", item.Id, attachment.Id)
+				attachments[item.Attachments[i].File] = data
+			}
+		} else {
+			item.Attachments = nil
+		}
+
+		manifest.Items = append(manifest.Items, item)
+	}
+
+	return manifest, attachments, nil
+}
+
+func archiveItem(data PasswordResponseData) (ArchiveItem, error) {
+	password, err := base64.StdEncoding.DecodeString(data.CryptedPassword.Reveal())
+	if err != nil {
+		return ArchiveItem{}, fmt.Errorf("decode password: %w", err)
+	}
+
+	item := ArchiveItem{
+		Id:                 data.Id,
+		FolderId:           data.FolderId,
+		Name:               data.Name,
+		Login:              data.Login,
+		Password:           SecretFromBytes(password),
+		Url:                data.Url,
+		Description:        data.Description,
+		Color:              data.Color,
+		Tags:               data.Tags,
+		Custom:             data.Custom,
+		LastPasswordUpdate: data.LastPasswordUpdate,
+		UpdatedAt:          data.UpdatedAt,
+	}
+	for _, attachment := range data.Attachments {
+		item.Attachments = append(item.Attachments, ArchiveAttachment{Id: attachment.Id, Name: attachment.Name})
+	}
+
+	return item, nil
+}
+
+// FolderPaths resolves the folder names from the vault root down to every
+// folder of data, keyed by folder ID. Parents missing from data are the vault
+// root, a parent cycle ends the path at the first folder seen twice.
+func FolderPaths(data []FolderResponseData) map[string][]string {
+	byId := make(map[string]FolderResponseData, len(data))
+	for _, folder := range data {
+		byId[folder.Id] = folder
+	}
+
+	paths := make(map[string][]string, len(data))
+	for _, folder := range data {
+		names := []string{folder.Name}
+		visited := map[string]bool{folder.Id: true}
+		for parent, ok := byId[folder.ParentId]; ok && !visited[parent.Id]; parent, ok = byId[parent.ParentId] {
+			visited[parent.Id] = true
+			names = append(names, parent.Name)
+		}
+		slices.Reverse(names)
+		paths[folder.Id] = names
+	}
+
+	return paths
+}
+
+// archiveFolders converts folders and resolves their name path, parents first
+func archiveFolders(data []FolderResponseData) []ArchiveFolder {
+	paths := FolderPaths(data)
+
+	folders := make([]ArchiveFolder, 0, len(data))
+	for _, folder := range data {
+		folders = append(folders, ArchiveFolder{
+			Id:       folder.Id,
+			ParentId: folder.ParentId,
+			Name:     folder.Name,
+			Path:     paths[folder.Id],
+		})
+	}
+
+	// Parents must be created before their children on import
+	slices.SortStableFunc(folders, func(a, b ArchiveFolder) int {
+		return len(a.Path) - len(b.Path)
+	})
+
+	return folders
+}
@@ -0,0 +1,89 @@
+package passwork
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/treasure33/passwork-client-go/internal/archive"
+)
+
+func (suite *PassworkTestSuite) TestExport() {
+	suite.Run("ExportVault", func() {
+		var buf bytes.Buffer
+		opts := ExportOptions{Passphrase: "provider-test-passphrase", Iterations: 1000}
+
+		err := suite.client.ExportVault(context.Background(), suite.VaultId, &buf, opts)
+		if !suite.NoError(err) {
+			return
+		}
+
+		reader, err := archive.Read(&buf, opts.Passphrase)
+		if !suite.NoError(err) {
+			return
+		}
+
+		var manifest ArchiveManifest
+		if suite.NoError(reader.DecodeManifest(&manifest)) {
+			suite.Equal(ArchiveVersion, manifest.Version, "Manifest version should be the current archive version.")
+			suite.Equal(suite.VaultId, manifest.Vault.Id, "Manifest vault should be the exported vault.")
+			for _, item := range manifest.Items {
+				for _, attachment := range item.Attachments {
+					suite.Contains(reader.Files, attachment.File, "Archive should contain every referenced attachment.")
+				}
+			}
+		}
+	})
+}
+
+func TestArchiveFoldersCycle(t *testing.T) {
+	folders := archiveFolders([]FolderResponseData{
+		{Id: "a", ParentId: "b", Name: "A"},
+		{Id: "b", ParentId: "a", Name: "B"},
+		{Id: "c", ParentId: "a", Name: "C"},
+		{Id: "d", ParentId: "d", Name: "D"},
+	})
+
+	paths := make(map[string][]string)
+	for _, folder := range folders {
+		paths[folder.Id] = folder.Path
+	}
+	assert.Equal(t, []string{"B", "A"}, paths["a"])
+	assert.Equal(t, []string{"A", "B"}, paths["b"])
+	assert.Equal(t, []string{"B", "A", "C"}, paths["c"])
+	assert.Equal(t, []string{"D"}, paths["d"])
+}
+
+func TestSnapshotVaultCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vaults/v1":
+			w.Write([]byte(`{"status":"success","data":{"id":"v1","name":"vault"}}`))
+		case "/folders/search":
+			w.Write([]byte(`{"status":"success","data":[]}`))
+		case "/items/search":
+			w.Write([]byte(`{"status":"success","data":[{"id":"pw1","vaultId":"v1","name":"db"}]}`))
+		default:
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL, "key", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.SnapshotVault(ctx, "v1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "Fetching items should stop when ctx is done.")
+}
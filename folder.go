@@ -2,6 +2,7 @@ package passwork
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,12 +12,35 @@ import (
 )
 
 func (c *Client) GetFolder(folderId string) (FolderResponse, error) {
-	url := fmt.Sprintf("%s/folders/%s", c.BaseURL, folderId)
-	method := http.MethodGet
+	return c.GetFolderContext(context.Background(), folderId)
+}
+
+// GetFolderContext is GetFolder bound to ctx
+func (c *Client) GetFolderContext(ctx context.Context, folderId string) (FolderResponse, error) {
+	call := &Call{
+		Operation: "GetFolder",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/folders/%s", c.BaseURL, folderId),
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (FolderResponse, error) {
+		return c.getFolder(ctx, call, folderId)
+	})
+}
+
+func (c *Client) getFolder(ctx context.Context, call *Call, folderId string) (FolderResponse, error) {
 	var responseObject FolderResponse
 	var err error
 
-	response, _, err := c.sendRequest(method, url, nil)
+	if c.cacheLookup(cacheKindFolder, folderId, &responseObject) {
+		return responseObject, nil
+	}
+
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if errors.Is(err, ErrOffline) {
+		if data, ok := c.offline.folder(folderId); ok {
+			return FolderResponse{Status: "success", Data: data, Stale: true}, nil
+		}
+	}
 	if err != nil {
 		return responseObject, err
 	}
@@ -27,17 +51,38 @@ func (c *Client) GetFolder(folderId string) (FolderResponse, error) {
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheStore(cacheKindFolder, folderId, responseObject)
+	c.offlineStore(func(s *OfflineSnapshot) error { return s.storeFolder(responseObject.Data) })
+
 	return responseObject, nil
 }
 
 func (c *Client) SearchFolder(request FolderSearchRequest) (FolderSearchResponse, error) {
-	url := fmt.Sprintf("%s/folders/search", c.BaseURL)
-	method := http.MethodPost
+	return c.searchFolderContext(context.Background(), request)
+}
+
+func (c *Client) searchFolderContext(ctx context.Context, request FolderSearchRequest) (FolderSearchResponse, error) {
+	call := &Call{
+		Operation: "SearchFolder",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/folders/search", c.BaseURL),
+		Request:   &request,
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (FolderSearchResponse, error) {
+		return c.searchFolder(ctx, call)
+	})
+}
+
+func (c *Client) searchFolder(ctx context.Context, call *Call) (FolderSearchResponse, error) {
 	var responseObject FolderSearchResponse
-	var err error
+
+	request, err := callRequest[FolderSearchRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
 
 	body, err := json.Marshal(request)
 	if err != nil {
@@ -45,7 +90,7 @@ func (c *Client) SearchFolder(request FolderSearchRequest) (FolderSearchResponse
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -57,17 +102,35 @@ func (c *Client) SearchFolder(request FolderSearchRequest) (FolderSearchResponse
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
 	return responseObject, nil
 }
 
 func (c *Client) AddFolder(folderRequest FolderRequest) (FolderResponse, error) {
-	url := fmt.Sprintf("%s/folders", c.BaseURL)
-	method := http.MethodPost
+	return c.addFolderContext(context.Background(), folderRequest)
+}
+
+func (c *Client) addFolderContext(ctx context.Context, folderRequest FolderRequest) (FolderResponse, error) {
+	call := &Call{
+		Operation: "AddFolder",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/folders", c.BaseURL),
+		Request:   &folderRequest,
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (FolderResponse, error) {
+		return c.addFolder(ctx, call)
+	})
+}
+
+func (c *Client) addFolder(ctx context.Context, call *Call) (FolderResponse, error) {
 	var responseObject FolderResponse
-	var err error
+
+	folderRequest, err := callRequest[FolderRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
 
 	body, err := json.Marshal(folderRequest)
 	if err != nil {
@@ -75,7 +138,7 @@ func (c *Client) AddFolder(folderRequest FolderRequest) (FolderResponse, error)
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -87,24 +150,42 @@ func (c *Client) AddFolder(folderRequest FolderRequest) (FolderResponse, error)
 	}
 
 	if responseObject.Status != "success" && responseObject.Code != "folderCreated" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheInvalidate(cacheKindVault, folderRequest.VaultId)
+	c.cacheInvalidate(cacheKindFolder, folderRequest.ParentId)
+
 	return responseObject, nil
 }
 
 func (c *Client) EditFolder(folderId string, request FolderRequest) (FolderResponse, error) {
-	url := fmt.Sprintf("%s/folders/%s", c.BaseURL, folderId)
-	method := http.MethodPut
+	call := &Call{
+		Operation: "EditFolder",
+		Method:    http.MethodPut,
+		URL:       fmt.Sprintf("%s/folders/%s", c.BaseURL, folderId),
+		Request:   &request,
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (FolderResponse, error) {
+		return c.editFolder(ctx, call, folderId)
+	})
+}
+
+func (c *Client) editFolder(ctx context.Context, call *Call, folderId string) (FolderResponse, error) {
 	var responseObject FolderResponse
 
+	request, err := callRequest[FolderRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
 	body, err := json.Marshal(request)
 	if err != nil {
 		return responseObject, err
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -116,19 +197,30 @@ func (c *Client) EditFolder(folderId string, request FolderRequest) (FolderRespo
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheInvalidate(cacheKindFolder, folderId)
+
 	return responseObject, nil
 }
 
 func (c *Client) DeleteFolder(folderId string) (DeleteResponse, error) {
-	url := fmt.Sprintf("%s/folders/%s", c.BaseURL, folderId)
-	method := http.MethodDelete
+	call := &Call{
+		Operation: "DeleteFolder",
+		Method:    http.MethodDelete,
+		URL:       fmt.Sprintf("%s/folders/%s", c.BaseURL, folderId),
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (DeleteResponse, error) {
+		return c.deleteFolder(ctx, call, folderId)
+	})
+}
+
+func (c *Client) deleteFolder(ctx context.Context, call *Call, folderId string) (DeleteResponse, error) {
 	var responseObject DeleteResponse
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, nil)
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
 	if err != nil {
 		return responseObject, err
 	}
@@ -140,8 +232,12 @@ func (c *Client) DeleteFolder(folderId string) (DeleteResponse, error) {
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	// Subfolders and items are deleted with the folder
+	c.cacheInvalidateKind(cacheKindVault, cacheKindFolder, cacheKindPassword)
+	c.offlineUpdate(func(s *OfflineSnapshot) error { return s.removeFolder(folderId) })
+
 	return responseObject, nil
 }
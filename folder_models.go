@@ -4,6 +4,7 @@ type FolderResponse struct {
 	Status string
 	Code   string // folderCreated, folderRenamed
 	Data   FolderResponseData
+	Stale  bool `json:"-"` // Served from the offline snapshot
 }
 
 type FolderSearchResponse struct {
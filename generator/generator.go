@@ -0,0 +1,256 @@
+// Package generator generates random passwords and passphrases with crypto/rand.
+package generator
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultLength = 20
+	DefaultWords  = 6
+
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits    = "0123456789"
+	symbols   = "!#$%&*+-./:;=?@^_~"
+	ambiguous = "0O1lI|`'\""
+)
+
+// EFF large wordlist, https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases (CC BY 3.0)
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+var defaultWordlist = sync.OnceValue(func() []string {
+	return strings.Fields(effLargeWordlist)
+})
+
+// Policy describes generated passwords. The zero value generates passwords of
+// DefaultLength from all character classes.
+type Policy struct {
+	// Length of the password, defaults to DefaultLength
+	Length int
+
+	// Character classes to use, all of them if none is set
+	Lowercase bool
+	Uppercase bool
+	Digits    bool
+	Symbols   bool
+
+	// Required number of characters per class, a minimum enables the class
+	MinLowercase int
+	MinUppercase int
+	MinDigits    int
+	MinSymbols   int
+
+	// ExcludeAmbiguous leaves out characters that are easily confused like 0, O, 1, l and I
+	ExcludeAmbiguous bool
+
+	// Exclude leaves out additional characters
+	Exclude string
+
+	// SymbolSet replaces the default symbols, it must only contain printable ASCII characters
+	SymbolSet string
+}
+
+type class struct {
+	chars string
+	min   int
+}
+
+// classes returns the enabled character classes with exclusions applied
+func (p Policy) classes() ([]class, error) {
+	symbolSet := symbols
+	if p.SymbolSet != "" {
+		symbolSet = p.SymbolSet
+	}
+	// Characters are picked by byte
+	for _, r := range symbolSet {
+		if r < ' ' || r > '~' {
+			return nil, fmt.Errorf("generator: symbol %q is not printable ASCII", r)
+		}
+	}
+
+	all := []struct {
+		name    string
+		chars   string
+		enabled bool
+		min     int
+	}{
+		{"lowercase", lowercase, p.Lowercase, p.MinLowercase},
+		{"uppercase", uppercase, p.Uppercase, p.MinUppercase},
+		{"digits", digits, p.Digits, p.MinDigits},
+		{"symbols", symbolSet, p.Symbols, p.MinSymbols},
+	}
+	none := !p.Lowercase && !p.Uppercase && !p.Digits && !p.Symbols &&
+		p.MinLowercase == 0 && p.MinUppercase == 0 && p.MinDigits == 0 && p.MinSymbols == 0
+
+	var classes []class
+	for _, c := range all {
+		if c.min < 0 {
+			return nil, fmt.Errorf("generator: negative minimum for %s", c.name)
+		}
+		if !none && !c.enabled && c.min == 0 {
+			continue
+		}
+
+		chars := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(p.Exclude, r) || (p.ExcludeAmbiguous && strings.ContainsRune(ambiguous, r)) {
+				return -1
+			}
+			return r
+		}, c.chars)
+		if chars == "" {
+			return nil, fmt.Errorf("generator: all %s are excluded", c.name)
+		}
+		classes = append(classes, class{chars: chars, min: c.min})
+	}
+	return classes, nil
+}
+
+func (p Policy) length() int {
+	if p.Length <= 0 {
+		return DefaultLength
+	}
+	return p.Length
+}
+
+// Validate reports whether passwords can be generated with the policy
+func (p Policy) Validate() error {
+	classes, err := p.classes()
+	if err != nil {
+		return err
+	}
+	required := 0
+	for _, c := range classes {
+		required += c.min
+	}
+	if required > p.length() {
+		return fmt.Errorf("generator: %d required characters exceed length %d", required, p.length())
+	}
+	return nil
+}
+
+// Password generates a password following the policy
+func Password(p Policy) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	classes, _ := p.classes()
+
+	var pool strings.Builder
+	password := make([]byte, 0, p.length())
+	for _, c := range classes {
+		pool.WriteString(c.chars)
+		for range c.min {
+			char, err := pick(c.chars)
+			if err != nil {
+				return "", err
+			}
+			password = append(password, char)
+		}
+	}
+	for len(password) < p.length() {
+		char, err := pick(pool.String())
+		if err != nil {
+			return "", err
+		}
+		password = append(password, char)
+	}
+
+	// Required characters were placed first, spread them
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+// PassphrasePolicy describes generated passphrases
+type PassphrasePolicy struct {
+	// Words in the passphrase, defaults to DefaultWords
+	Words int
+
+	// Separator between words, defaults to "-"
+	Separator string
+
+	// Capitalize the first letter of every word
+	Capitalize bool
+
+	// Digit appends a random digit to one of the words
+	Digit bool
+
+	// Wordlist to pick from, defaults to the EFF large wordlist
+	Wordlist []string
+}
+
+// Passphrase generates a passphrase following the policy
+func Passphrase(p PassphrasePolicy) (string, error) {
+	count := p.Words
+	if count <= 0 {
+		count = DefaultWords
+	}
+	separator := p.Separator
+	if separator == "" {
+		separator = "-"
+	}
+	wordlist := p.Wordlist
+	if len(wordlist) == 0 {
+		wordlist = defaultWordlist()
+	}
+	if len(wordlist) < 2 {
+		return "", errors.New("generator: wordlist needs at least two words")
+	}
+
+	words := make([]string, count)
+	for i := range words {
+		n, err := randomInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = wordlist[n]
+		if p.Capitalize && words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	if p.Digit {
+		i, err := randomInt(count)
+		if err != nil {
+			return "", err
+		}
+		digit, err := pick(digits)
+		if err != nil {
+			return "", err
+		}
+		words[i] += string(digit)
+	}
+
+	return strings.Join(words, separator), nil
+}
+
+func pick(chars string) (byte, error) {
+	n, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[n], nil
+}
+
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+func countIn(s, chars string) int {
+	count := 0
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPasswordDefaults(t *testing.T) {
+	password, err := Password(Policy{})
+	require.NoError(t, err)
+	assert.Len(t, password, DefaultLength)
+
+	other, err := Password(Policy{})
+	require.NoError(t, err)
+	assert.NotEqual(t, password, other)
+}
+
+func TestPasswordClasses(t *testing.T) {
+	policy := Policy{Length: 12, Digits: true, MinUppercase: 3, MinSymbols: 2, SymbolSet: "#!"}
+	for range 50 {
+		password, err := Password(policy)
+		require.NoError(t, err)
+		assert.Len(t, password, 12)
+		assert.GreaterOrEqual(t, countIn(password, uppercase), 3)
+		assert.GreaterOrEqual(t, countIn(password, "#!"), 2)
+		assert.Zero(t, countIn(password, lowercase), "lowercase is not enabled")
+		assert.Equal(t, 12, countIn(password, uppercase+digits+"#!"))
+	}
+}
+
+func TestPasswordExclusions(t *testing.T) {
+	policy := Policy{Length: 200, ExcludeAmbiguous: true, Exclude: "xyz"}
+	password, err := Password(policy)
+	require.NoError(t, err)
+	assert.Zero(t, countIn(password, ambiguous+"xyz"))
+
+	_, err = Password(Policy{Digits: true, Exclude: digits})
+	assert.Error(t, err)
+	_, err = Password(Policy{Length: 4, MinDigits: 3, MinSymbols: 2})
+	assert.Error(t, err)
+	_, err = Password(Policy{MinDigits: -1})
+	assert.Error(t, err)
+	_, err = Password(Policy{Symbols: true, SymbolSet: "€§"})
+	assert.EqualError(t, err, `generator: symbol '€' is not printable ASCII`)
+}
+
+func TestPassphrase(t *testing.T) {
+	passphrase, err := Passphrase(PassphrasePolicy{})
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(passphrase, "-"), DefaultWords)
+	assert.Len(t, defaultWordlist(), 7776)
+
+	passphrase, err = Passphrase(PassphrasePolicy{Words: 4, Separator: " ", Capitalize: true, Digit: true, Wordlist: []string{"alpha", "beta"}})
+	require.NoError(t, err)
+	words := strings.Split(passphrase, " ")
+	require.Len(t, words, 4)
+	for _, word := range words {
+		assert.Contains(t, []string{"Alpha", "Beta"}, strings.TrimRight(word, digits))
+	}
+	assert.Equal(t, 1, countIn(passphrase, digits))
+
+	_, err = Passphrase(PassphrasePolicy{Wordlist: []string{"single"}})
+	assert.Error(t, err)
+}
+
+func TestForVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"passwordPolicy":{"minLength":32,"requireDigits":true,"requireSymbols":true}}}`))
+	}))
+	defer server.Close()
+
+	policy, err := ForVault(passwork.NewClient(server.URL, "key", time.Second), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, 32, policy.Length)
+	assert.Equal(t, 1, policy.MinDigits)
+	assert.Equal(t, 1, policy.MinSymbols)
+	assert.Zero(t, policy.MinUppercase)
+
+	request, err := Fill(passwork.PasswordRequest{Name: "generated", VaultId: "v1"}, policy)
+	require.NoError(t, err)
+	password, err := base64.StdEncoding.DecodeString(request.CryptedPassword.Reveal())
+	require.NoError(t, err)
+	assert.Len(t, password, 32)
+	assert.GreaterOrEqual(t, countIn(string(password), digits), 1)
+}
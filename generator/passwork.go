@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+// FromVaultPolicy returns a policy generating passwords the vault accepts.
+// All character classes stay enabled, required classes get a minimum of one.
+func FromVaultPolicy(policy passwork.PasswordPolicyData) Policy {
+	p := Policy{
+		Length:    max(DefaultLength, policy.MinLength),
+		Lowercase: true,
+		Uppercase: true,
+		Digits:    true,
+		Symbols:   true,
+	}
+	if policy.RequireLowercase {
+		p.MinLowercase = 1
+	}
+	if policy.RequireUppercase {
+		p.MinUppercase = 1
+	}
+	if policy.RequireDigits {
+		p.MinDigits = 1
+	}
+	if policy.RequireSymbols {
+		p.MinSymbols = 1
+	}
+	return p
+}
+
+// ForVault reads the password policy of a vault
+func ForVault(client *passwork.Client, vaultId string) (Policy, error) {
+	settings, err := client.GetVaultSettings(vaultId)
+	if err != nil {
+		return Policy{}, fmt.Errorf("generator: get vault settings: %w", err)
+	}
+	return FromVaultPolicy(settings.Data.PasswordPolicy), nil
+}
+
+// Fill generates a password for request and stores it base64 encoded as AddPassword expects
+func Fill(request passwork.PasswordRequest, policy Policy) (passwork.PasswordRequest, error) {
+	password, err := Password(policy)
+	if err != nil {
+		return request, err
+	}
+	request.CryptedPassword = passwork.NewSecret(base64.StdEncoding.EncodeToString([]byte(password)))
+	return request, nil
+}
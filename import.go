@@ -0,0 +1,323 @@
+package passwork
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/treasure33/passwork-client-go/internal/archive"
+)
+
+// dryRunPrefix marks the placeholder target IDs of a dry run
+const dryRunPrefix = "dry-run:"
+
+// ImportVault Recreate the folders and items of an archive inside the target vault
+// Archive IDs are remapped to the IDs of the newly created objects. Failures of
+// single objects are recorded in the report and don't abort the import.
+func (c *Client) ImportVault(ctx context.Context, r io.Reader, targetVaultId string, opts ImportOptions) (ImportReport, error) {
+	reader, err := archive.Read(r, opts.Passphrase)
+	if err != nil {
+		return ImportReport{Mapping: resumeMapping(opts)}, err
+	}
+
+	var manifest ArchiveManifest
+	if err := reader.DecodeManifest(&manifest); err != nil {
+		return ImportReport{Mapping: resumeMapping(opts)}, fmt.Errorf("import: decode manifest: %w", err)
+	}
+
+	return c.ImportManifest(ctx, manifest, reader.Files, targetVaultId, opts)
+}
+
+// ImportManifest Recreate the folders and items of a manifest inside the target vault
+// files holds the attachment blobs keyed by ArchiveAttachment.File. opts.Passphrase is not used.
+func (c *Client) ImportManifest(ctx context.Context, manifest ArchiveManifest, files map[string][]byte, targetVaultId string, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{Mapping: resumeMapping(opts)}
+
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictSkip
+	}
+	switch opts.Conflict {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return report, fmt.Errorf("import: unknown conflict strategy %q", opts.Conflict)
+	}
+
+	if manifest.Version > ArchiveVersion {
+		return report, fmt.Errorf("import: unsupported archive version %d", manifest.Version)
+	}
+
+	existing, err := c.indexVault(ctx, targetVaultId)
+	if err != nil {
+		return report, err
+	}
+
+	imp := importer{
+		client:   c,
+		vaultId:  targetVaultId,
+		opts:     opts,
+		report:   &report,
+		existing: existing,
+		files:    files,
+		folders:  make(map[string]bool, len(manifest.Folders)),
+		paths:    make(map[string][]string),
+	}
+	for _, folder := range manifest.Folders {
+		imp.folders[folder.Id] = true
+	}
+
+	for _, folder := range manifest.Folders {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		imp.importFolder(ctx, folder)
+	}
+
+	for _, item := range manifest.Items {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		imp.importItem(ctx, item)
+	}
+
+	return report, nil
+}
+
+// resumeMapping copies the mapping of a previous run. Targets a dry run
+// reported are placeholders, a real import creates those objects.
+func resumeMapping(opts ImportOptions) map[string]string {
+	mapping := make(map[string]string, len(opts.Mapping))
+	for sourceId, targetId := range opts.Mapping {
+		if opts.DryRun || !strings.HasPrefix(targetId, dryRunPrefix) {
+			mapping[sourceId] = targetId
+		}
+	}
+	return mapping
+}
+
+// vaultIndex locates existing objects of a vault by their position and name
+type vaultIndex struct {
+	folders map[string]string // folder path -> id
+	items   map[string]string // folder id + name -> id
+}
+
+func itemKey(folderId, name string) string {
+	return folderId + "\x00" + name
+}
+
+func folderKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func (c *Client) indexVault(ctx context.Context, vaultId string) (vaultIndex, error) {
+	index := vaultIndex{folders: make(map[string]string), items: make(map[string]string)}
+
+	folders, err := c.searchFolderContext(ctx, FolderSearchRequest{VaultId: vaultId})
+	if err != nil {
+		return index, fmt.Errorf("import: list folders: %w", err)
+	}
+	for _, folder := range archiveFolders(folders.Data) {
+		index.folders[folderKey(folder.Path)] = folder.Id
+	}
+
+	items, err := c.SearchPasswordContext(ctx, PasswordSearchRequest{VaultId: vaultId})
+	if err != nil {
+		return index, fmt.Errorf("import: list items: %w", err)
+	}
+	for _, item := range items.Data {
+		index.items[itemKey(item.FolderId, item.Name)] = item.Id
+	}
+
+	return index, nil
+}
+
+type importer struct {
+	client   *Client
+	vaultId  string
+	opts     ImportOptions
+	report   *ImportReport
+	existing vaultIndex
+	files    map[string][]byte
+
+	// IDs of the folders in the manifest, other parents are the vault root
+	folders map[string]bool
+
+	// Target paths of imported folders, renamed folders change the path of their children
+	paths map[string][]string
+}
+
+func (imp *importer) importFolder(ctx context.Context, folder ArchiveFolder) {
+	result := ImportResult{Kind: "folder", SourceId: folder.Id, Name: folder.Name}
+
+	parentId := ""
+	if imp.folders[folder.ParentId] && folder.ParentId != folder.Id {
+		var ok bool
+		if parentId, ok = imp.report.Mapping[folder.ParentId]; !ok {
+			result.Err = fmt.Errorf("parent folder %s was not imported", folder.ParentId)
+			imp.report.Failed = append(imp.report.Failed, result)
+			return
+		}
+	}
+	path := append(append([]string{}, imp.paths[folder.ParentId]...), folder.Name)
+
+	if targetId, ok := imp.report.Mapping[folder.Id]; ok {
+		imp.paths[folder.Id] = path
+		result.TargetId = targetId
+		result.Reason = "already imported"
+		imp.report.Skipped = append(imp.report.Skipped, result)
+		return
+	}
+
+	if targetId, ok := imp.existing.folders[folderKey(path)]; ok {
+		if imp.opts.Conflict != ConflictRename {
+			// Folders carry no content, reuse them for skip and overwrite alike
+			imp.paths[folder.Id] = path
+			imp.report.Mapping[folder.Id] = targetId
+			result.TargetId = targetId
+			result.Reason = "exists"
+			imp.report.Skipped = append(imp.report.Skipped, result)
+			return
+		}
+
+		result.Name = uniqueName(folder.Name, func(name string) bool {
+			_, ok := imp.existing.folders[folderKey(append(path[:len(path)-1:len(path)-1], name))]
+			return ok
+		})
+		path = append(path[:len(path)-1:len(path)-1], result.Name)
+	}
+
+	imp.paths[folder.Id] = path
+
+	if imp.opts.DryRun {
+		result.TargetId = dryRunPrefix + folder.Id
+	} else {
+		response, err := imp.client.addFolderContext(ctx, FolderRequest{VaultId: imp.vaultId, Name: result.Name, ParentId: parentId})
+		if err != nil {
+			result.Err = err
+			imp.report.Failed = append(imp.report.Failed, result)
+			return
+		}
+		result.TargetId = response.Data.Id
+	}
+
+	imp.existing.folders[folderKey(path)] = result.TargetId
+	imp.report.Mapping[folder.Id] = result.TargetId
+	imp.report.Created = append(imp.report.Created, result)
+}
+
+func (imp *importer) importItem(ctx context.Context, item ArchiveItem) {
+	result := ImportResult{Kind: "item", SourceId: item.Id, Name: item.Name}
+
+	if targetId, ok := imp.report.Mapping[item.Id]; ok {
+		result.TargetId = targetId
+		result.Reason = "already imported"
+		imp.report.Skipped = append(imp.report.Skipped, result)
+		return
+	}
+
+	// Items in folders outside the manifest go to the vault root, like such folders
+	folderId := ""
+	if imp.folders[item.FolderId] {
+		var ok bool
+		if folderId, ok = imp.report.Mapping[item.FolderId]; !ok {
+			result.Err = fmt.Errorf("folder %s was not imported", item.FolderId)
+			imp.report.Failed = append(imp.report.Failed, result)
+			return
+		}
+	}
+
+	request, err := imp.passwordRequest(item, folderId)
+	if err != nil {
+		result.Err = err
+		imp.report.Failed = append(imp.report.Failed, result)
+		return
+	}
+
+	existingId, exists := imp.existing.items[itemKey(folderId, item.Name)]
+	if exists {
+		switch imp.opts.Conflict {
+		case ConflictSkip:
+			result.TargetId = existingId
+			result.Reason = "exists"
+			imp.report.Mapping[item.Id] = existingId
+			imp.report.Skipped = append(imp.report.Skipped, result)
+			return
+		case ConflictRename:
+			request.Name = uniqueName(item.Name, func(name string) bool {
+				_, ok := imp.existing.items[itemKey(folderId, name)]
+				return ok
+			})
+			result.Name = request.Name
+			exists = false
+		}
+	}
+
+	switch {
+	case imp.opts.DryRun && exists:
+		result.TargetId = existingId
+		result.Reason = "overwritten"
+	case imp.opts.DryRun:
+		result.TargetId = dryRunPrefix + item.Id
+	case exists:
+		response, err := imp.client.editPasswordContext(ctx, existingId, request)
+		if err != nil {
+			result.Err = err
+			imp.report.Failed = append(imp.report.Failed, result)
+			return
+		}
+		result.TargetId = response.Data.Id
+		result.Reason = "overwritten"
+	default:
+		response, err := imp.client.addPasswordContext(ctx, request)
+		if err != nil {
+			result.Err = err
+			imp.report.Failed = append(imp.report.Failed, result)
+			return
+		}
+		result.TargetId = response.Data.Id
+	}
+
+	imp.existing.items[itemKey(folderId, result.Name)] = result.TargetId
+	imp.report.Mapping[item.Id] = result.TargetId
+	imp.report.Created = append(imp.report.Created, result)
+}
+
+func (imp *importer) passwordRequest(item ArchiveItem, folderId string) (PasswordRequest, error) {
+	request := PasswordRequest{
+		Name:            item.Name,
+		Login:           item.Login,
+		CryptedPassword: NewSecret(base64.StdEncoding.EncodeToString(item.Password.Bytes())),
+		Url:             item.Url,
+		Description:     item.Description,
+		Custom:          item.Custom,
+		Color:           item.Color,
+		Tags:            item.Tags,
+		VaultId:         imp.vaultId,
+		FolderId:        folderId,
+	}
+
+	for _, attachment := range item.Attachments {
+		data, ok := imp.files[attachment.File]
+		if !ok {
+			return request, errors.New("attachment " + attachment.Name + " missing from archive")
+		}
+		request.Attachments = append(request.Attachments, PasswordAttachmentData{
+			Name:          attachment.Name,
+			EncryptedData: base64.StdEncoding.EncodeToString(data),
+		})
+	}
+
+	return request, nil
+}
+
+// uniqueName appends a counter to name until taken reports it as free
+func uniqueName(name string, taken func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package passwork
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *PassworkTestSuite) TestImport() {
+	var buf bytes.Buffer
+	passphrase := "provider-test-passphrase"
+
+	err := suite.client.ExportVault(context.Background(), suite.VaultId, &buf, ExportOptions{Passphrase: passphrase, Iterations: 1000})
+	if !suite.NoError(err) {
+		return
+	}
+	archive := buf.Bytes()
+
+	suite.Run("DryRunSkip", func() {
+		opts := ImportOptions{Passphrase: passphrase, Conflict: ConflictSkip, DryRun: true}
+
+		report, err := suite.client.ImportVault(context.Background(), bytes.NewReader(archive), suite.VaultId, opts)
+
+		if suite.NoError(err) {
+			suite.Empty(report.Created, "Importing a vault into itself should not create anything.")
+			suite.Empty(report.Failed, "Importing a vault into itself should not fail.")
+		}
+	})
+
+	suite.Run("DryRunRename", func() {
+		opts := ImportOptions{Passphrase: passphrase, Conflict: ConflictRename, DryRun: true}
+
+		report, err := suite.client.ImportVault(context.Background(), bytes.NewReader(archive), suite.VaultId, opts)
+
+		if suite.NoError(err) {
+			suite.Empty(report.Skipped, "Renaming should not skip any object.")
+			suite.Empty(report.Failed, "Importing a vault into itself should not fail.")
+			for _, result := range report.Created {
+				if result.Kind == "item" {
+					suite.Contains(result.Name, " (", "Conflicting items should be renamed.")
+				}
+			}
+		}
+	})
+
+	suite.Run("Resume", func() {
+		opts := ImportOptions{Passphrase: passphrase, DryRun: true}
+		first, err := suite.client.ImportVault(context.Background(), bytes.NewReader(archive), suite.VaultId, opts)
+		if !suite.NoError(err) {
+			return
+		}
+
+		opts.Mapping = first.Mapping
+		report, err := suite.client.ImportVault(context.Background(), bytes.NewReader(archive), suite.VaultId, opts)
+
+		if suite.NoError(err) {
+			suite.Empty(report.Created, "Resumed import should not recreate mapped objects.")
+			suite.Equal(first.Mapping, report.Mapping, "Resumed import should keep the mapping.")
+		}
+	})
+
+	suite.Run("WrongPassphrase", func() {
+		_, err := suite.client.ImportVault(context.Background(), bytes.NewReader(archive), suite.VaultId, ImportOptions{Passphrase: "wrong"})
+
+		suite.Error(err, "ImportVault() should fail with a wrong passphrase.")
+	})
+}
+
+func TestImportManifestFailedParent(t *testing.T) {
+	var mu sync.Mutex
+	var created []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case r.URL.Path == "/folders/search" || r.URL.Path == "/items/search":
+			w.Write([]byte(`{"status":"success","data":[]}`))
+		case r.URL.Path == "/folders" && body["name"] == "broken":
+			w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+		default:
+			created = append(created, body)
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": map[string]any{"id": "new-" + body["name"].(string)}})
+		}
+	}))
+	defer server.Close()
+
+	manifest := ArchiveManifest{
+		Version: ArchiveVersion,
+		Folders: []ArchiveFolder{
+			{Id: "f1", ParentId: "vault", Name: "broken"},
+			{Id: "f2", ParentId: "f1", Name: "child"},
+			{Id: "f3", ParentId: "vault", Name: "fine"},
+		},
+		Items: []ArchiveItem{
+			{Id: "i1", FolderId: "f2", Name: "item"},
+			{Id: "i2", FolderId: "vault", Name: "orphan"},
+		},
+	}
+	client := NewClient(server.URL, "key", time.Second)
+
+	report, err := client.ImportManifest(context.Background(), manifest, nil, "v1", ImportOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Failed, 3)
+	assert.EqualError(t, report.Failed[1].Err, "parent folder f1 was not imported")
+	assert.EqualError(t, report.Failed[2].Err, "folder f2 was not imported")
+	require.Len(t, created, 2)
+	assert.Equal(t, "fine", created[0]["name"])
+	assert.Equal(t, "orphan", created[1]["name"], "Items in folders outside the manifest should go to the vault root.")
+	assert.Empty(t, created[1]["folderId"])
+	assert.Equal(t, map[string]string{"f3": "new-fine", "i2": "new-orphan"}, report.Mapping)
+
+	// Requests use the import context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.ImportManifest(ctx, manifest, nil, "v1", ImportOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "import: list folders")
+}
+
+func TestImportManifestIgnoresDryRunMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/search", "/items/search":
+			w.Write([]byte(`{"status":"success","data":[]}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":{"id":"new"}}`))
+		}
+	}))
+	defer server.Close()
+
+	manifest := ArchiveManifest{Version: ArchiveVersion, Items: []ArchiveItem{{Id: "i1", Name: "item"}, {Id: "i2", Name: "done"}}}
+	client := NewClient(server.URL, "key", time.Second)
+
+	dryRun, err := client.ImportManifest(context.Background(), manifest, nil, "v1", ImportOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, "dry-run:i1", dryRun.Mapping["i1"])
+
+	mapping := dryRun.Mapping
+	mapping["i2"] = "existing"
+	report, err := client.ImportManifest(context.Background(), manifest, nil, "v1", ImportOptions{Mapping: mapping})
+	require.NoError(t, err)
+
+	require.Len(t, report.Created, 1)
+	assert.Equal(t, "i1", report.Created[0].SourceId)
+	assert.Equal(t, map[string]string{"i1": "new", "i2": "existing"}, report.Mapping)
+}
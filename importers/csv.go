@@ -0,0 +1,198 @@
+// Package importers converts password manager CSV exports into Passwork
+// requests and writes Passwork vault contents back out as CSV.
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+// Record is a single credential independent of any CSV layout
+type Record struct {
+	Name     string
+	Url      string
+	Login    string
+	Password passwork.Secret
+	Notes    string
+	Folder   []string // Folder names from the vault root down
+	Tags     []string
+	Custom   []passwork.PasswordCustomData
+}
+
+// ReadCSV parses all rows of r using format. If format has no columns the
+// layout is detected from the header row.
+func ReadCSV(r io.Reader, format Format) ([]Record, Format, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, format, fmt.Errorf("importers: read header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	if len(format.Columns) == 0 {
+		var ok bool
+		if format, ok = DetectFormat(header); !ok {
+			return nil, format, errors.New("importers: unknown CSV layout")
+		}
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[column] = i
+	}
+	mapped := make(map[string]bool, len(format.Columns))
+	for _, column := range format.Columns {
+		mapped[column] = true
+	}
+
+	var records []Record
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, format, fmt.Errorf("importers: line %d: %w", line, err)
+		}
+
+		value := func(field Field) string {
+			column, ok := format.Columns[field]
+			if !ok {
+				return ""
+			}
+			i, ok := index[column]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return row[i]
+		}
+
+		record := Record{
+			Name:     value(FieldName),
+			Url:      value(FieldUrl),
+			Login:    value(FieldLogin),
+			Password: passwork.NewSecret(value(FieldPassword)),
+			Notes:    value(FieldNotes),
+			Folder:   splitFolder(value(FieldFolder), format),
+			Tags:     splitTags(value(FieldTags), format),
+		}
+		if totp := value(FieldTotp); totp != "" {
+			record.Custom = append(record.Custom, passwork.PasswordCustomData{Name: "TOTP", Value: passwork.NewSecret(totp), Type: "totp"})
+		}
+		if format.CustomFields {
+			for i, column := range header {
+				if !mapped[column] && i < len(row) && row[i] != "" {
+					record.Custom = append(record.Custom, passwork.PasswordCustomData{Name: column, Value: passwork.NewSecret(row[i]), Type: "text"})
+				}
+			}
+		}
+
+		if record.Name == "" {
+			record.Name = record.Url
+		}
+		if record.Name == "" && record.Login == "" && record.Password.IsEmpty() {
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	return records, format, nil
+}
+
+// WriteCSV writes records in the layout of format, including its header row
+func WriteCSV(w io.Writer, format Format, records []Record) error {
+	writer := csv.NewWriter(w)
+
+	// Custom fields of every record become extra columns after the header
+	header := slices.Clone(format.Header)
+	if format.CustomFields {
+		for _, record := range records {
+			for _, custom := range record.Custom {
+				if custom.Type != "totp" && !slices.Contains(header, custom.Name) {
+					header = append(header, custom.Name)
+				}
+			}
+		}
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		values := map[Field]string{
+			FieldName:     record.Name,
+			FieldUrl:      record.Url,
+			FieldLogin:    record.Login,
+			FieldPassword: record.Password.Reveal(),
+			FieldNotes:    record.Notes,
+			FieldFolder:   strings.Join(record.Folder, format.folderSeparator()),
+			FieldTags:     strings.Join(record.Tags, format.tagSeparator()),
+		}
+		for _, custom := range record.Custom {
+			if custom.Type == "totp" {
+				values[FieldTotp] = custom.Value.Reveal()
+			}
+		}
+		if format.RootFolder != "" {
+			values[FieldFolder] = strings.Join(append([]string{format.RootFolder}, record.Folder...), format.folderSeparator())
+		}
+
+		byColumn := make(map[string]string, len(format.Columns))
+		for field, column := range format.Columns {
+			byColumn[column] = values[field]
+		}
+		if format.CustomFields {
+			for _, custom := range record.Custom {
+				if _, ok := byColumn[custom.Name]; !ok && custom.Type != "totp" {
+					byColumn[custom.Name] = custom.Value.Reveal()
+				}
+			}
+		}
+
+		row := make([]string, len(header))
+		for i, column := range header {
+			row[i] = byColumn[column]
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func splitFolder(value string, format Format) []string {
+	var folder []string
+	for _, name := range strings.Split(value, format.folderSeparator()) {
+		if name = strings.TrimSpace(name); name != "" {
+			folder = append(folder, name)
+		}
+	}
+	if len(folder) > 0 && format.RootFolder != "" && folder[0] == format.RootFolder {
+		folder = folder[1:]
+	}
+	return folder
+}
+
+func splitTags(value string, format Format) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, format.tagSeparator()) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,146 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"name,url,login,password,notes,folder,tags", "generic"},
+		{"name,url,login,password", "generic"},
+		{"folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp", "bitwarden"},
+		{"url,username,password,totp,extra,name,grouping,fav", "lastpass"},
+		{"Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes", "1password"},
+		{`"Group","Title","Username","Password","URL","Notes","TOTP","Icon","Last Modified","Created"`, "keepassxc"},
+		{"name,url,username,password,note", "chrome"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			_, format, err := ReadCSV(strings.NewReader(tt.header+"\n"), Format{})
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, format.Name)
+			}
+		})
+	}
+
+	_, _, err := ReadCSV(strings.NewReader("foo,bar\n"), Format{})
+	assert.Error(t, err)
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Run("lastpass", func(t *testing.T) {
+		input := "url,username,password,totp,extra,name,grouping,fav\n" +
+			"https://example.com,jo,s3cret,JBSWY3DP,note,Example,Work\\Infra,0\n"
+
+		records, _, err := ReadCSV(strings.NewReader(input), LastPass)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		assert.Equal(t, "Example", records[0].Name)
+		assert.Equal(t, "jo", records[0].Login)
+		assert.Equal(t, "s3cret", records[0].Password.Reveal())
+		assert.Equal(t, "note", records[0].Notes)
+		assert.Equal(t, []string{"Work", "Infra"}, records[0].Folder)
+		assert.Equal(t, "JBSWY3DP", records[0].Custom[0].Value.Reveal())
+	})
+
+	t.Run("keepassxc root group", func(t *testing.T) {
+		input := "Group,Title,Username,Password,URL,Notes,TOTP,Icon,Last Modified,Created\n" +
+			"Root/Servers,db,admin,pw,,,,0,,\n"
+
+		records, _, err := ReadCSV(strings.NewReader(input), Format{})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		assert.Equal(t, []string{"Servers"}, records[0].Folder)
+	})
+
+	t.Run("generic tags and custom columns", func(t *testing.T) {
+		input := "name,login,password,tags,environment\n" +
+			"api,svc,token,\"prod, api\",production\n" +
+			",,,,\n"
+
+		records, _, err := ReadCSV(strings.NewReader(input), Generic)
+		require.NoError(t, err)
+		require.Len(t, records, 1, "Empty rows should be dropped.")
+
+		assert.Equal(t, []string{"prod", "api"}, records[0].Tags)
+		require.Len(t, records[0].Custom, 1)
+		assert.Equal(t, "environment", records[0].Custom[0].Name)
+		assert.Equal(t, "production", records[0].Custom[0].Value.Reveal())
+	})
+}
+
+func TestWriteCSVRoundTrip(t *testing.T) {
+	records := []Record{
+		{Name: "a", Url: "https://a", Login: "la", Password: passwork.NewSecret("p,a\""), Notes: "multi\nline", Folder: []string{"x", "y"}, Tags: []string{"t1", "t2"}},
+		{Name: "b", Login: "lb", Password: passwork.NewSecret("pb"), Custom: []passwork.PasswordCustomData{
+			{Name: "environment", Value: passwork.NewSecret("production"), Type: "text"},
+		}},
+	}
+
+	for _, format := range []Format{Generic, KeePassXC} {
+		t.Run(format.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteCSV(&buf, format, records))
+
+			got, _, err := ReadCSV(&buf, format)
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+
+			assert.Equal(t, records[0].Password.Reveal(), got[0].Password.Reveal())
+			assert.Equal(t, records[0].Notes, got[0].Notes)
+			assert.Equal(t, records[0].Folder, got[0].Folder)
+			assert.Empty(t, got[1].Folder)
+
+			if format.CustomFields {
+				require.Len(t, got[1].Custom, 1)
+				assert.Equal(t, "environment", got[1].Custom[0].Name)
+				assert.Equal(t, "production", got[1].Custom[0].Value.Reveal())
+				assert.Empty(t, got[0].Custom)
+			}
+		})
+	}
+}
+
+func TestFolderHierarchy(t *testing.T) {
+	records := []Record{
+		{Name: "a", Folder: []string{"x", "y"}},
+		{Name: "b", Folder: []string{"x"}},
+		{Name: "c", Folder: []string{"z"}},
+		{Name: "d"},
+	}
+
+	nodes := FolderHierarchy("vault", records)
+
+	var paths []string
+	for _, node := range nodes {
+		paths = append(paths, strings.Join(node.Path, "/"))
+		assert.Equal(t, "vault", node.Request.VaultId)
+		assert.Equal(t, node.Path[len(node.Path)-1], node.Request.Name)
+	}
+	assert.Equal(t, []string{"x", "x/y", "z"}, paths)
+}
+
+func TestPasswordRequest(t *testing.T) {
+	record := Record{Name: "a", Login: "l", Password: passwork.NewSecret("secret"), Notes: "n", Tags: []string{"t"}}
+
+	request := record.PasswordRequest("vault", "folder")
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("secret")), request.CryptedPassword.Reveal())
+	assert.Equal(t, "n", request.Description)
+	assert.Equal(t, "vault", request.VaultId)
+	assert.Equal(t, "folder", request.FolderId)
+	assert.Equal(t, []string{"t"}, request.Tags)
+}
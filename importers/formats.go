@@ -0,0 +1,174 @@
+package importers
+
+import "strings"
+
+// Field is a column role in a CSV layout
+type Field int
+
+const (
+	FieldName Field = iota
+	FieldUrl
+	FieldLogin
+	FieldPassword
+	FieldNotes
+	FieldFolder
+	FieldTags
+	FieldTotp
+)
+
+// Format describes the CSV layout of a password manager export
+type Format struct {
+	Name string
+
+	// Columns maps every known field to its header in the CSV file
+	Columns map[Field]string
+
+	// Header is the full header row written on export, in order
+	Header []string
+
+	// FolderSeparator splits nested folder names, defaults to "/"
+	FolderSeparator string
+
+	// RootFolder is a top level group name that is dropped on import
+	RootFolder string
+
+	// TagSeparator splits the tags column, defaults to ","
+	TagSeparator string
+
+	// CustomFields keeps unmapped columns as custom fields
+	CustomFields bool
+}
+
+var (
+	Generic = Format{
+		Name: "generic",
+		Columns: map[Field]string{
+			FieldName:     "name",
+			FieldUrl:      "url",
+			FieldLogin:    "login",
+			FieldPassword: "password",
+			FieldNotes:    "notes",
+			FieldFolder:   "folder",
+			FieldTags:     "tags",
+		},
+		Header:       []string{"name", "url", "login", "password", "notes", "folder", "tags"},
+		CustomFields: true,
+	}
+
+	Bitwarden = Format{
+		Name: "bitwarden",
+		Columns: map[Field]string{
+			FieldName:     "name",
+			FieldUrl:      "login_uri",
+			FieldLogin:    "login_username",
+			FieldPassword: "login_password",
+			FieldNotes:    "notes",
+			FieldFolder:   "folder",
+			FieldTotp:     "login_totp",
+		},
+		Header: []string{"folder", "favorite", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"},
+	}
+
+	LastPass = Format{
+		Name: "lastpass",
+		Columns: map[Field]string{
+			FieldName:     "name",
+			FieldUrl:      "url",
+			FieldLogin:    "username",
+			FieldPassword: "password",
+			FieldNotes:    "extra",
+			FieldFolder:   "grouping",
+			FieldTotp:     "totp",
+		},
+		Header:          []string{"url", "username", "password", "totp", "extra", "name", "grouping", "fav"},
+		FolderSeparator: "\\",
+	}
+
+	OnePassword = Format{
+		Name: "1password",
+		Columns: map[Field]string{
+			FieldName:     "Title",
+			FieldUrl:      "Url",
+			FieldLogin:    "Username",
+			FieldPassword: "Password",
+			FieldNotes:    "Notes",
+			FieldTags:     "Tags",
+			FieldTotp:     "OTPAuth",
+		},
+		Header: []string{"Title", "Url", "Username", "Password", "OTPAuth", "Favorite", "Archived", "Tags", "Notes"},
+	}
+
+	KeePassXC = Format{
+		Name: "keepassxc",
+		Columns: map[Field]string{
+			FieldName:     "Title",
+			FieldUrl:      "URL",
+			FieldLogin:    "Username",
+			FieldPassword: "Password",
+			FieldNotes:    "Notes",
+			FieldFolder:   "Group",
+			FieldTotp:     "TOTP",
+		},
+		Header:     []string{"Group", "Title", "Username", "Password", "URL", "Notes", "TOTP", "Icon", "Last Modified", "Created"},
+		RootFolder: "Root",
+	}
+
+	Chrome = Format{
+		Name: "chrome",
+		Columns: map[Field]string{
+			FieldName:     "name",
+			FieldUrl:      "url",
+			FieldLogin:    "username",
+			FieldPassword: "password",
+			FieldNotes:    "note",
+		},
+		Header: []string{"name", "url", "username", "password", "note"},
+	}
+
+	// Formats lists all built-in layouts
+	Formats = []Format{Bitwarden, LastPass, KeePassXC, OnePassword, Chrome, Generic}
+)
+
+// DetectFormat returns the built-in format that best matches the header row.
+// A format is a candidate when its name, login and password columns are present,
+// candidates are ranked by the share of their columns found in the header.
+func DetectFormat(header []string) (Format, bool) {
+	present := make(map[string]bool, len(header))
+	for _, column := range header {
+		present[strings.TrimSpace(column)] = true
+	}
+
+	var best Format
+	bestScore := 0.0
+	for _, format := range Formats {
+		if !present[format.Columns[FieldName]] || !present[format.Columns[FieldLogin]] || !present[format.Columns[FieldPassword]] {
+			continue
+		}
+
+		matched := 0
+		for _, column := range format.Header {
+			if present[column] {
+				matched++
+			}
+		}
+		if score := float64(matched) / float64(len(format.Header)); score > bestScore {
+			best, bestScore = format, score
+		}
+	}
+
+	return best, bestScore > 0
+}
+
+func (f Format) folderSeparator() string {
+	if f.FolderSeparator == "" {
+		return "/"
+	}
+	return f.FolderSeparator
+}
+
+func (f Format) tagSeparator() string {
+	if f.TagSeparator == "" {
+		return ","
+	}
+	return f.TagSeparator
+}
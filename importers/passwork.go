@@ -0,0 +1,175 @@
+package importers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+// PasswordRequest converts the record into a request for AddPassword
+func (r Record) PasswordRequest(vaultId, folderId string) passwork.PasswordRequest {
+	return passwork.PasswordRequest{
+		Name:            r.Name,
+		Login:           r.Login,
+		CryptedPassword: passwork.NewSecret(base64.StdEncoding.EncodeToString(r.Password.Bytes())),
+		Url:             r.Url,
+		Description:     r.Notes,
+		Custom:          r.Custom,
+		Tags:            r.Tags,
+		VaultId:         vaultId,
+		FolderId:        folderId,
+	}
+}
+
+// FolderNode is a folder to create, parents are listed before their children
+type FolderNode struct {
+	Path    []string
+	Request passwork.FolderRequest // ParentId is filled in once the parent exists
+}
+
+// FolderHierarchy returns the folders needed to hold records, parents first
+func FolderHierarchy(vaultId string, records []Record) []FolderNode {
+	var nodes []FolderNode
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		for depth := 1; depth <= len(record.Folder); depth++ {
+			path := record.Folder[:depth]
+			key := strings.Join(path, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			nodes = append(nodes, FolderNode{
+				Path:    path,
+				Request: passwork.FolderRequest{VaultId: vaultId, Name: path[depth-1]},
+			})
+		}
+	}
+
+	return nodes
+}
+
+type ImportResult struct {
+	Created int
+	Folders int
+	Failed  []ImportError
+}
+
+// ImportError is a record, or a folder when Folder is set, that couldn't be created
+type ImportError struct {
+	Record Record
+	Folder []string
+	Err    error
+}
+
+func (e ImportError) Error() string {
+	if e.Folder != nil {
+		return fmt.Sprintf("folder %s: %v", strings.Join(e.Folder, "/"), e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Record.Name, e.Err)
+}
+
+// Import creates the folder hierarchy and items of records inside a vault.
+// Existing folders with the same path are reused. A folder that can't be
+// created is reported in Failed and its subfolders and records are skipped.
+func Import(ctx context.Context, client *passwork.Client, vaultId string, records []Record) (ImportResult, error) {
+	var result ImportResult
+
+	existing, err := client.SearchFolder(passwork.FolderSearchRequest{VaultId: vaultId})
+	if err != nil {
+		return result, err
+	}
+	folderIds := make(map[string]string, len(existing.Data))
+	for id, path := range passwork.FolderPaths(existing.Data) {
+		folderIds[strings.Join(path, "\x00")] = id
+	}
+
+	// failed holds the path of every folder that wasn't created, with the
+	// path of the folder that failed itself
+	failed := make(map[string][]string)
+	for _, node := range FolderHierarchy(vaultId, records) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		key := strings.Join(node.Path, "\x00")
+		if _, ok := folderIds[key]; ok {
+			continue
+		}
+
+		parent := strings.Join(node.Path[:len(node.Path)-1], "\x00")
+		if cause, ok := failed[parent]; ok {
+			failed[key] = cause
+			continue
+		}
+
+		node.Request.ParentId = folderIds[parent]
+		response, err := client.AddFolder(node.Request)
+		if err != nil {
+			failed[key] = node.Path
+			result.Failed = append(result.Failed, ImportError{Folder: node.Path, Err: err})
+			continue
+		}
+		folderIds[key] = response.Data.Id
+		result.Folders++
+	}
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if cause, ok := failed[strings.Join(record.Folder, "\x00")]; ok {
+			err := fmt.Errorf("folder %s was not created", strings.Join(cause, "/"))
+			result.Failed = append(result.Failed, ImportError{Record: record, Err: err})
+			continue
+		}
+
+		request := record.PasswordRequest(vaultId, folderIds[strings.Join(record.Folder, "\x00")])
+		if _, err := client.AddPassword(request); err != nil {
+			result.Failed = append(result.Failed, ImportError{Record: record, Err: err})
+			continue
+		}
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// FromManifest converts the items of a vault snapshot into records
+func FromManifest(manifest passwork.ArchiveManifest) []Record {
+	paths := make(map[string][]string, len(manifest.Folders))
+	for _, folder := range manifest.Folders {
+		paths[folder.Id] = folder.Path
+	}
+
+	records := make([]Record, 0, len(manifest.Items))
+	for _, item := range manifest.Items {
+		records = append(records, Record{
+			Name:     item.Name,
+			Url:      item.Url,
+			Login:    item.Login,
+			Password: item.Password,
+			Notes:    item.Description,
+			Folder:   paths[item.FolderId],
+			Tags:     item.Tags,
+			Custom:   item.Custom,
+		})
+	}
+
+	return records
+}
+
+// Export writes all items of a vault as CSV in the given layout
+func Export(ctx context.Context, client *passwork.Client, vaultId string, w io.Writer, format Format) error {
+	manifest, err := client.SnapshotVault(ctx, vaultId)
+	if err != nil {
+		return err
+	}
+	return WriteCSV(w, format, FromManifest(manifest))
+}
@@ -0,0 +1,58 @@
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+func TestImportFailedFolder(t *testing.T) {
+	var created []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case r.URL.Path == "/folders/search":
+			w.Write([]byte(`{"status":"success","data":[
+				{"id":"f1","vaultId":"v1","name":"Servers"},
+				{"id":"f2","vaultId":"v1","parentId":"f1","name":"Linux"}
+			]}`))
+		case r.URL.Path == "/folders" && body["name"] == "broken":
+			w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+		default:
+			created = append(created, body)
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": map[string]any{"id": "new-" + body["name"].(string)}})
+		}
+	}))
+	defer server.Close()
+
+	records := []Record{
+		{Name: "web", Folder: []string{"Servers", "Linux"}},
+		{Name: "lost", Folder: []string{"broken", "child"}},
+		{Name: "db", Folder: []string{"fine"}},
+	}
+	client := passwork.NewClient(server.URL, "key", time.Second)
+
+	result, err := Import(context.Background(), client, "v1", records)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Folders)
+	assert.Equal(t, 2, result.Created)
+	require.Len(t, result.Failed, 2)
+	assert.Equal(t, []string{"broken"}, result.Failed[0].Folder)
+	assert.Equal(t, "lost", result.Failed[1].Record.Name)
+	assert.EqualError(t, result.Failed[1].Err, "folder broken was not created")
+
+	require.Len(t, created, 3)
+	assert.Equal(t, "fine", created[0]["name"])
+	assert.Equal(t, "f2", created[1]["folderId"], "Existing folders should be reused.")
+	assert.Equal(t, "new-fine", created[2]["folderId"])
+}
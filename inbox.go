@@ -0,0 +1,179 @@
+package passwork
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/treasure33/passwork-client-go/internal/utils"
+)
+
+// ListInbox List all items shared directly to the current user
+func (c *Client) ListInbox() (InboxListResponse, error) {
+	call := &Call{
+		Operation: "ListInbox",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/inbox/items", c.BaseURL),
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (InboxListResponse, error) {
+		return c.listInbox(ctx, call)
+	})
+}
+
+func (c *Client) listInbox(ctx context.Context, call *Call) (InboxListResponse, error) {
+	var responseObject InboxListResponse
+	var err error
+
+	// HTTP request
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[InboxListResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// API v1 doesn't return Status field
+	if responseObject.Status != "" && responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
+
+// AcceptInboxItem Move an inbox item into a vault and optional folder
+func (c *Client) AcceptInboxItem(inboxItemId, targetVaultId, targetFolderId string) (InboxResponse, error) {
+	request := InboxAcceptRequest{
+		VaultId:  targetVaultId,
+		FolderId: targetFolderId,
+	}
+	call := &Call{
+		Operation: "AcceptInboxItem",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/inbox/items/%s/accept", c.BaseURL, inboxItemId),
+		Request:   &request,
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (InboxResponse, error) {
+		return c.acceptInboxItem(ctx, call)
+	})
+}
+
+func (c *Client) acceptInboxItem(ctx context.Context, call *Call) (InboxResponse, error) {
+	var responseObject InboxResponse
+
+	request, err := callRequest[InboxAcceptRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// HTTP request
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[InboxResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	if responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
+
+// DeclineInboxItem Reject an inbox item, removing it from the inbox
+func (c *Client) DeclineInboxItem(inboxItemId string) (InboxOperationResponse, error) {
+	call := &Call{
+		Operation: "DeclineInboxItem",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/inbox/items/%s/decline", c.BaseURL, inboxItemId),
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (InboxOperationResponse, error) {
+		return c.declineInboxItem(ctx, call, inboxItemId)
+	})
+}
+
+func (c *Client) declineInboxItem(ctx context.Context, call *Call, inboxItemId string) (InboxOperationResponse, error) {
+	var responseObject InboxOperationResponse
+
+	// HTTP request
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[InboxOperationResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	if responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
+
+// SendToInbox Share a password directly to another user's inbox
+func (c *Client) SendToInbox(pwId, userId string) (InboxOperationResponse, error) {
+	request := InboxSendRequest{
+		ItemId: pwId,
+		UserId: userId,
+	}
+	call := &Call{
+		Operation: "SendToInbox",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/inbox/items", c.BaseURL),
+		Request:   &request,
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (InboxOperationResponse, error) {
+		return c.sendToInbox(ctx, call)
+	})
+}
+
+func (c *Client) sendToInbox(ctx context.Context, call *Call) (InboxOperationResponse, error) {
+	var responseObject InboxOperationResponse
+
+	request, err := callRequest[InboxSendRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// HTTP request
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[InboxOperationResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	if responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
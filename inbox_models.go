@@ -0,0 +1,63 @@
+package passwork
+
+import "encoding/json"
+
+type InboxListResponse struct {
+	Status string
+	Code   string
+	Data   []InboxResponseData
+}
+
+// UnmarshalJSON implements custom unmarshaling to support both API v1 and v4 formats
+// API v1 uses "items" field, API v4 uses "Data" field
+func (i *InboxListResponse) UnmarshalJSON(data []byte) error {
+	var v1Format struct {
+		Items []InboxResponseData `json:"items"`
+	}
+	if err := json.Unmarshal(data, &v1Format); err == nil && v1Format.Items != nil {
+		i.Data = v1Format.Items
+		return nil
+	}
+
+	type Alias InboxListResponse
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(i),
+	}
+	return json.Unmarshal(data, aux)
+}
+
+type InboxResponse struct {
+	Status string
+	Code   string // inboxItemAccepted
+	Data   PasswordResponseData
+}
+
+type InboxOperationResponse struct {
+	Status string
+	Code   string // inboxItemSent, inboxItemDeclined
+	Data   string
+}
+
+type InboxResponseData struct {
+	Id         string
+	ItemId     string
+	Name       string
+	Login      string
+	Url        string
+	SenderId   string
+	SenderName string
+	CreatedAt  string
+	Item       PasswordResponseData
+}
+
+type InboxAcceptRequest struct {
+	VaultId  string `json:"vaultId"`
+	FolderId string `json:"folderId,omitempty"`
+}
+
+type InboxSendRequest struct {
+	ItemId string `json:"itemId"`
+	UserId string `json:"userId"`
+}
@@ -0,0 +1,56 @@
+package passwork
+
+func (suite *PassworkTestSuite) TestInbox() {
+	var passwordId string
+	var inboxItemId string
+
+	suite.Run("Send", func() {
+		if suite.InboxUserId == "" {
+			suite.T().Skip("PASSWORK_INBOX_USER_ID not set")
+		}
+
+		password, err := suite.client.AddPassword(PasswordRequest{
+			Name:            "provider-test-inbox-entry",
+			VaultId:         suite.VaultId,
+			CryptedPassword: NewSecret("cHJvdmlkZXItdGVzdC1wYXNzd29yZA=="),
+		})
+		if !suite.NoError(err) {
+			return
+		}
+		passwordId = password.Data.Id
+
+		result, err := suite.client.SendToInbox(passwordId, suite.InboxUserId)
+
+		if suite.NoError(err) {
+			suite.Equal("success", result.Status, "SendToInbox() should return success.")
+		}
+	})
+
+	suite.Run("List", func() {
+		result, err := suite.client.ListInbox()
+
+		if suite.NoError(err) {
+			for _, item := range result.Data {
+				if item.ItemId == passwordId || item.Item.Id == passwordId {
+					inboxItemId = item.Id
+				}
+			}
+		}
+	})
+
+	suite.Run("Decline", func() {
+		if inboxItemId == "" {
+			suite.T().Skip("no inbox item to decline")
+		}
+
+		result, err := suite.client.DeclineInboxItem(inboxItemId)
+
+		if suite.NoError(err) {
+			suite.Equal("success", result.Status, "DeclineInboxItem() should return success.")
+		}
+	})
+
+	if passwordId != "" {
+		suite.client.DeletePassword(passwordId)
+	}
+}
@@ -0,0 +1,46 @@
+package passwork
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// Instrumentation observes the logical operations of a client and their HTTP
+// attempts. The otelpasswork module implements it with OpenTelemetry.
+type Instrumentation interface {
+	// StartOperation is called when an operation such as GetPassword starts.
+	// The returned context is passed on to its HTTP attempts, end is called
+	// with the API code of the response and the error once it completes.
+	StartOperation(ctx context.Context, operation string) (context.Context, func(code string, err error))
+
+	// StartAttempt is called before an HTTP request is sent and may add
+	// headers to it. end is called with the response status, 0 if none was received.
+	StartAttempt(req *http.Request) func(status int, err error)
+}
+
+// WithInstrumentation reports operations and HTTP attempts to instrument
+func WithInstrumentation(instrument Instrumentation) Option {
+	return func(c *Client) {
+		c.instrument = instrument
+	}
+}
+
+func (c *Client) startAttempt(req *http.Request) func(status int, err error) {
+	if c.instrument == nil {
+		return func(int, error) {}
+	}
+	return c.instrument.StartAttempt(req)
+}
+
+// apiCode returns the Code field of a response, e.g. passwordNull or folderCreated
+func apiCode(response any) string {
+	value := reflect.ValueOf(response)
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	if code := value.FieldByName("Code"); code.IsValid() && code.Kind() == reflect.String {
+		return code.String()
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package passwork
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type operationKey struct{}
+
+type recordingInstrumentation struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingInstrumentation) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingInstrumentation) StartOperation(ctx context.Context, operation string) (context.Context, func(string, error)) {
+	r.record("start " + operation)
+	return context.WithValue(ctx, operationKey{}, operation), func(code string, err error) {
+		if err != nil {
+			r.record("end " + operation + " " + code + " error")
+			return
+		}
+		r.record("end " + operation + " " + code)
+	}
+}
+
+func (r *recordingInstrumentation) StartAttempt(req *http.Request) func(int, error) {
+	operation, _ := req.Context().Value(operationKey{}).(string)
+	req.Header.Set("X-Operation", operation)
+	r.record("attempt " + req.Method + " " + req.URL.Path)
+	return func(status int, err error) {
+		r.record("attempt end " + http.StatusText(status))
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("X-Operation"))
+		switch r.URL.Path {
+		case "/folders":
+			w.Write([]byte(`{"status":"success","code":"folderCreated","data":{"id":"f1"}}`))
+		default:
+			w.Write([]byte(`{"status":"error","code":"passwordNull"}`))
+		}
+	}))
+	defer server.Close()
+
+	instrument := &recordingInstrumentation{}
+	client := NewClient(server.URL, "key", time.Second, WithInstrumentation(instrument))
+
+	_, err := client.AddFolder(FolderRequest{Name: "f", VaultId: "v1"})
+	require.NoError(t, err)
+	_, err = client.GetPassword("pw1")
+	require.EqualError(t, err, "passwordNull")
+
+	assert.Equal(t, []string{
+		"start AddFolder",
+		"attempt POST /folders",
+		"attempt end OK",
+		"end AddFolder folderCreated",
+		"start GetPassword",
+		"attempt GET /items/pw1",
+		"attempt end OK",
+		"end GetPassword passwordNull error",
+	}, instrument.events)
+}
+
+func TestInstrumentationUnreachable(t *testing.T) {
+	instrument := &recordingInstrumentation{}
+	client := NewClient("http://127.0.0.1:1", "key", time.Second, WithInstrumentation(instrument))
+
+	_, err := client.DeleteVault("v1")
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"start DeleteVault",
+		"attempt DELETE /vaults/v1",
+		"attempt end ",
+		"end DeleteVault  error",
+	}, instrument.events)
+}
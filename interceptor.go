@@ -0,0 +1,99 @@
+package passwork
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Call is a logical operation passing through the interceptor chain
+type Call struct {
+	// Operation is the name of the client method, e.g. GetPassword
+	Operation string
+	Method    string
+	URL       string
+
+	// Request points to the request sent as body, e.g. *PasswordRequest, or
+	// for searches to the parameters encoded into the query of URL. It is
+	// read after the interceptors ran, so they may modify it in place or
+	// replace it with another pointer of the same type.
+	// It is nil for operations without a request.
+	Request any
+
+	// Header is added to the HTTP request, overriding the client's headers
+	Header http.Header
+}
+
+// Invoker performs a call and returns the response of the operation, e.g. PasswordResponse
+type Invoker func(ctx context.Context, call *Call) (any, error)
+
+// Interceptor wraps every operation of a client. It may modify the call in
+// place before passing it to next, return without calling next to
+// short-circuit the operation, and inspect or replace the response and error.
+// A returned response must have the type the operation returns.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) (any, error)
+
+// WithInterceptors adds interceptors to the client, the first one added is the outermost
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+type callKey struct{}
+
+// invoke runs do as the operation described by call, through the
+// instrumentation and the interceptor chain
+func invoke[T any](ctx context.Context, c *Client, call *Call, do func(ctx context.Context) (T, error)) (T, error) {
+	var end func(string, error)
+	if c.instrument != nil {
+		ctx, end = c.instrument.StartOperation(ctx, call.Operation)
+	}
+
+	original := call
+	var invoker Invoker = func(ctx context.Context, call *Call) (any, error) {
+		if call != original {
+			*original = *call
+		}
+		return do(context.WithValue(ctx, callKey{}, original))
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) (any, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	result, err := invoker(ctx, call)
+	response, ok := result.(T)
+	if !ok && result != nil {
+		err = fmt.Errorf("passwork: interceptor returned %T from %s, expected %T", result, call.Operation, response)
+	}
+
+	if end != nil {
+		end(apiCode(response), err)
+	}
+	return response, err
+}
+
+// callRequest returns the request of call as the interceptors left it
+func callRequest[T any](call *Call) (T, error) {
+	var request T
+	switch r := call.Request.(type) {
+	case *T:
+		if r != nil {
+			return *r, nil
+		}
+	case T:
+		return r, nil
+	}
+	return request, fmt.Errorf("passwork: interceptor set %T as request of %s, expected *%T", call.Request, call.Operation, request)
+}
+
+// callHeader returns the headers interceptors added to the call of ctx
+func callHeader(ctx context.Context) http.Header {
+	if call, ok := ctx.Value(callKey{}).(*Call); ok {
+		return call.Header
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package passwork
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptors(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		received = append(received, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Gateway")+" "+r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/items":
+			assert.Equal(t, "renamed", body["name"])
+			assert.Equal(t, "c2VjcmV0", body["cryptedPassword"])
+			w.Write([]byte(`{"status":"success","data":{"id":"pw1","name":"renamed"}}`))
+		default:
+			w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+		}
+	}))
+	defer server.Close()
+
+	var seen []string
+	audit := func(ctx context.Context, call *Call, next Invoker) (any, error) {
+		response, err := next(ctx, call)
+		seen = append(seen, call.Operation+" "+call.Method+" "+call.URL[len(server.URL):])
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			seen = append(seen, "api error "+apiErr.Code)
+		}
+		if response, ok := response.(PasswordResponse); ok && err == nil {
+			seen = append(seen, "response "+response.Data.Name)
+		}
+		return response, err
+	}
+	gateway := func(ctx context.Context, call *Call, next Invoker) (any, error) {
+		call.Header = http.Header{"X-Gateway": {"token"}, "Authorization": {"Gateway"}}
+		if request, ok := call.Request.(*PasswordRequest); ok {
+			request.Name = "renamed"
+		}
+		return next(ctx, call)
+	}
+
+	client := NewClient(server.URL, "key", time.Second, WithInterceptors(audit, gateway))
+
+	response, err := client.AddPassword(PasswordRequest{Name: "db", CryptedPassword: NewSecret("c2VjcmV0")})
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", response.Data.Name)
+
+	_, err = client.DeleteFolder("f1")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "accessDenied", apiErr.Code)
+	assert.EqualError(t, err, "accessDenied")
+
+	assert.Equal(t, []string{"POST /items token Gateway", "DELETE /folders/f1 token Gateway"}, received)
+	assert.Equal(t, []string{
+		"AddPassword POST /items",
+		"response renamed",
+		"DeleteFolder DELETE /folders/f1",
+		"api error accessDenied",
+	}, seen)
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	injected := errors.New("injected fault")
+	faults := func(ctx context.Context, call *Call, next Invoker) (any, error) {
+		switch call.Operation {
+		case "GetPassword":
+			return PasswordResponse{Status: "success", Data: PasswordResponseData{Id: "fake"}}, nil
+		case "SearchPassword":
+			assert.Equal(t, "db", call.Request.(*PasswordSearchRequest).Query)
+			return nil, injected
+		case "GetFolder":
+			return VaultResponse{}, nil
+		}
+		return next(ctx, call)
+	}
+	client := NewClient(server.URL, "key", time.Second, WithInterceptors(faults))
+
+	response, err := client.GetPassword("pw1")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", response.Data.Id)
+
+	_, err = client.SearchPassword(PasswordSearchRequest{Query: "db"})
+	assert.ErrorIs(t, err, injected)
+
+	_, err = client.GetFolder("f1")
+	assert.EqualError(t, err, "passwork: interceptor returned passwork.VaultResponse from GetFolder, expected passwork.FolderResponse")
+}
+
+func TestInterceptorReplacesCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/vaults/v1", r.URL.Path)
+		w.Write([]byte(`{"status":"success","data":"vaultDeleted"}`))
+	}))
+	defer server.Close()
+
+	reroute := func(ctx context.Context, call *Call, next Invoker) (any, error) {
+		rerouted := *call
+		rerouted.URL = server.URL + "/v2/vaults/v1"
+		return next(ctx, &rerouted)
+	}
+	client := NewClient(server.URL, "key", time.Second, WithInterceptors(reroute))
+
+	_, err := client.DeleteVault("v1")
+	require.NoError(t, err)
+}
+
+func TestInterceptorReplacesRequest(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		received = append(received, r.Method+" "+r.URL.RequestURI()+" "+fmt.Sprint(body["name"]))
+
+		switch r.URL.Path {
+		case "/items":
+			w.Write([]byte(`{"status":"success","data":{"id":"pw1","name":"replaced"}}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	rewrite := func(ctx context.Context, call *Call, next Invoker) (any, error) {
+		switch request := call.Request.(type) {
+		case *PasswordRequest:
+			replaced := *request
+			replaced.Name = "replaced"
+			call.Request = &replaced
+		case *PasswordSearchRequest:
+			request.VaultId = "v1"
+		case *FolderRequest:
+			call.Request = "folder"
+		}
+		return next(ctx, call)
+	}
+	client := NewClient(server.URL, "key", time.Second, WithInterceptors(rewrite))
+
+	_, err := client.AddPassword(PasswordRequest{Name: "db", CryptedPassword: NewSecret("c2VjcmV0")})
+	require.NoError(t, err)
+
+	_, err = client.SearchPassword(PasswordSearchRequest{Query: "db"})
+	require.NoError(t, err)
+
+	_, err = client.AddFolder(FolderRequest{VaultId: "v1", Name: "ops"})
+	assert.EqualError(t, err, "passwork: interceptor set string as request of AddFolder, expected *passwork.FolderRequest")
+
+	assert.Equal(t, []string{
+		"POST /items replaced",
+		"GET /items/search?query=db&vaultId=v1 <nil>",
+	}, received)
+}
@@ -0,0 +1,113 @@
+// Package archive implements the encrypted, versioned archive format used for
+// vault exports: a tar stream containing a JSON manifest and attachment blobs,
+// encrypted with a key derived from a passphrase.
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+const ManifestName = "manifest.json"
+
+type Writer struct {
+	enc *encryptWriter
+	tar *tar.Writer
+}
+
+// NewWriter starts a new encrypted archive on w
+func NewWriter(w io.Writer, passphrase string, iterations int) (*Writer, error) {
+	if passphrase == "" {
+		return nil, errors.New("archive: passphrase must not be empty")
+	}
+
+	enc, err := newEncryptWriter(w, passphrase, iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{enc: enc, tar: tar.NewWriter(enc)}, nil
+}
+
+// WriteManifest writes v as JSON manifest, it must be the first entry
+func (a *Writer) WriteManifest(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.WriteFile(ManifestName, data)
+}
+
+// WriteFile adds a file entry to the archive
+func (a *Writer) WriteFile(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := a.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.tar.Write(data)
+	return err
+}
+
+// Close finishes the tar stream and the final encrypted chunk
+func (a *Writer) Close() error {
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+	return a.enc.Close()
+}
+
+type Reader struct {
+	Manifest []byte
+	Files    map[string][]byte
+}
+
+// Read decrypts and unpacks a whole archive into memory
+func Read(r io.Reader, passphrase string) (*Reader, error) {
+	plaintext, err := decrypt(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Reader{Files: make(map[string][]byte)}
+	tr := tar.NewReader(bytes.NewReader(plaintext))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Name == ManifestName {
+			result.Manifest = data
+		} else {
+			result.Files[header.Name] = data
+		}
+	}
+
+	if result.Manifest == nil {
+		return nil, ErrInvalidArchive
+	}
+
+	return result, nil
+}
+
+// DecodeManifest unmarshals the archive manifest into v
+func (a *Reader) DecodeManifest(v any) error {
+	return json.Unmarshal(a.Manifest, v)
+}
@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeArchive(t *testing.T, blob []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "secret", 1000)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteManifest(map[string]int{"version": 1}))
+	require.NoError(t, w.WriteFile("attachments/a/b", blob))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Larger than one chunk to exercise chunking
+	blob := make([]byte, 3*chunkSize+17)
+	rand.Read(blob)
+
+	data := writeArchive(t, blob)
+
+	r, err := Read(bytes.NewReader(data), "secret")
+	require.NoError(t, err)
+
+	var manifest map[string]int
+	require.NoError(t, r.DecodeManifest(&manifest))
+	assert.Equal(t, 1, manifest["version"])
+	assert.Equal(t, blob, r.Files["attachments/a/b"])
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	data := writeArchive(t, []byte("blob"))
+
+	_, err := Read(bytes.NewReader(data), "wrong")
+	assert.ErrorIs(t, err, ErrDecryption)
+}
+
+func TestTruncated(t *testing.T) {
+	blob := make([]byte, 2*chunkSize)
+	data := writeArchive(t, blob)
+
+	_, err := Read(bytes.NewReader(data[:len(data)/2]), "secret")
+	assert.ErrorIs(t, err, ErrDecryption)
+}
+
+func TestNotAnArchive(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte("plain text")), "secret")
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
+
+func TestDeriveKey(t *testing.T) {
+	// First 32 bytes of the PBKDF2-HMAC-SHA256 test vectors of RFC 7914 section 11
+	key := DeriveKey("passwd", []byte("salt"), 1)
+	assert.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc", hex.EncodeToString(key))
+	key = DeriveKey("Password", []byte("NaCl"), 80000)
+	assert.Equal(t, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56", hex.EncodeToString(key))
+}
+
+func TestIterationBound(t *testing.T) {
+	data := writeArchive(t, []byte("blob"))
+	binary.BigEndian.PutUint32(data[len(magic)+1+saltSize:], math.MaxUint32)
+
+	_, err := Read(bytes.NewReader(data), "secret")
+	assert.EqualError(t, err, "archive: iteration count 4294967295 out of range")
+
+	_, err = NewWriter(&bytes.Buffer{}, "secret", MaxIterations+1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	magic             = "PWKARCH"
+	formatVersion     = 1
+	saltSize          = 16
+	noncePrefixSize   = 7
+	chunkSize         = 64 * 1024
+	DefaultIterations = 600000
+
+	// MaxIterations bounds the work an archive header can demand
+	MaxIterations = 10 * DefaultIterations
+)
+
+var (
+	ErrInvalidArchive = errors.New("archive: not a passwork archive")
+	ErrDecryption     = errors.New("archive: wrong passphrase or corrupted archive")
+)
+
+// DeriveKey derives a 256 bit key from the passphrase using PBKDF2-HMAC-SHA256
+func DeriveKey(passphrase string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, 32, sha256.New)
+}
+
+// nonce builds the per-chunk nonce: prefix || counter || last flag
+func nonce(prefix []byte, counter uint32, last bool) []byte {
+	n := make([]byte, noncePrefixSize+5)
+	copy(n, prefix)
+	binary.BigEndian.PutUint32(n[noncePrefixSize:], counter)
+	if last {
+		n[len(n)-1] = 1
+	}
+	return n
+}
+
+// encryptWriter encrypts a stream as a sequence of authenticated AES-GCM chunks.
+// The final chunk carries a flag in its nonce so truncation is detected.
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+func newEncryptWriter(w io.Writer, passphrase string, iterations int) (*encryptWriter, error) {
+	if iterations <= 0 {
+		iterations = DefaultIterations
+	}
+	if iterations > MaxIterations {
+		return nil, fmt.Errorf("archive: more than %d iterations", MaxIterations)
+	}
+
+	salt := make([]byte, saltSize)
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(DeriveKey(passphrase, salt, iterations))
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(magic)+1+saltSize+4+noncePrefixSize)
+	header = append(header, magic...)
+	header = append(header, formatVersion)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, uint32(iterations))
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, aead: aead, prefix: prefix}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(chunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		// Only flush once more data follows, the last chunk is written on Close
+		if len(e.buf) == chunkSize && len(p) > 0 {
+			if err := e.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.flush(true)
+}
+
+func (e *encryptWriter) flush(last bool) error {
+	sealed := e.aead.Seal(nil, nonce(e.prefix, e.counter, last), e.buf, nil)
+	e.counter++
+	e.buf = e.buf[:0]
+
+	if err := binary.Write(e.w, binary.BigEndian, uint32(len(sealed))); err != nil {
+		return err
+	}
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// decrypt reads and authenticates a whole encrypted stream
+func decrypt(r io.Reader, passphrase string) ([]byte, error) {
+	header := make([]byte, len(magic)+1+saltSize+4+noncePrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, ErrInvalidArchive
+	}
+	if version := header[len(magic)]; version != formatVersion {
+		return nil, fmt.Errorf("archive: unsupported format version %d", version)
+	}
+
+	offset := len(magic) + 1
+	salt := header[offset : offset+saltSize]
+	offset += saltSize
+	iterations := int(binary.BigEndian.Uint32(header[offset:]))
+	if iterations < 1 || iterations > MaxIterations {
+		return nil, fmt.Errorf("archive: iteration count %d out of range", iterations)
+	}
+	offset += 4
+	prefix := header[offset:]
+
+	aead, err := newAEAD(DeriveKey(passphrase, salt, iterations))
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext []byte
+	var next []byte
+	for counter := uint32(0); ; counter++ {
+		var length uint32
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, ErrDecryption
+		}
+		if length > chunkSize+uint32(aead.Overhead()) {
+			return nil, ErrDecryption
+		}
+		sealed := make([]byte, length)
+		if _, err := io.ReadFull(r, sealed); err != nil {
+			return nil, ErrDecryption
+		}
+
+		// Try the chunk as a regular chunk first, then as the final one
+		if next, err = aead.Open(next[:0], nonce(prefix, counter, false), sealed, nil); err == nil {
+			plaintext = append(plaintext, next...)
+			continue
+		}
+		if next, err = aead.Open(next[:0], nonce(prefix, counter, true), sealed, nil); err == nil {
+			plaintext = append(plaintext, next...)
+			return plaintext, nil
+		}
+		return nil, ErrDecryption
+	}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
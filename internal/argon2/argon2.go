@@ -0,0 +1,281 @@
+// Package argon2 implements the Argon2 key derivation function (RFC 9106) in
+// all three variants. golang.org/x/crypto/argon2 doesn't expose Argon2d, which
+// is the default KDF of KeePass databases.
+package argon2
+
+import (
+	"encoding/binary"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type Mode int
+
+const (
+	ModeD  Mode = 0
+	ModeI  Mode = 1
+	ModeID Mode = 2
+)
+
+// Version is the only supported Argon2 version, 0x13
+const Version = 0x13
+
+const (
+	blockLength = 128
+	syncPoints  = 4
+)
+
+type block [blockLength]uint64
+
+// Key derives a key of keyLen bytes. memory is given in KiB.
+func Key(mode Mode, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	if time < 1 {
+		panic("argon2: number of rounds too small")
+	}
+	if threads < 1 {
+		panic("argon2: parallelism degree too low")
+	}
+
+	h0 := initHash(mode, password, salt, secret, data, time, memory, uint32(threads), keyLen)
+
+	memory = memory / (syncPoints * uint32(threads)) * (syncPoints * uint32(threads))
+	if memory < 2*syncPoints*uint32(threads) {
+		memory = 2 * syncPoints * uint32(threads)
+	}
+
+	B := initBlocks(&h0, memory, uint32(threads))
+	processBlocks(mode, B, time, memory, uint32(threads))
+	return extractKey(B, memory, uint32(threads), keyLen)
+}
+
+func initHash(mode Mode, password, salt, secret, data []byte, time, memory, threads, keyLen uint32) [blake2b.Size + 8]byte {
+	var h0 [blake2b.Size + 8]byte
+	var params [24]byte
+
+	b2, _ := blake2b.New512(nil)
+	binary.LittleEndian.PutUint32(params[0:4], threads)
+	binary.LittleEndian.PutUint32(params[4:8], keyLen)
+	binary.LittleEndian.PutUint32(params[8:12], memory)
+	binary.LittleEndian.PutUint32(params[12:16], time)
+	binary.LittleEndian.PutUint32(params[16:20], Version)
+	binary.LittleEndian.PutUint32(params[20:24], uint32(mode))
+	b2.Write(params[:])
+	for _, input := range [][]byte{password, salt, secret, data} {
+		b2.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(input))))
+		b2.Write(input)
+	}
+	b2.Sum(h0[:0])
+
+	return h0
+}
+
+func initBlocks(h0 *[blake2b.Size + 8]byte, memory, threads uint32) []block {
+	var block0 [1024]byte
+	B := make([]block, memory)
+
+	for lane := uint32(0); lane < threads; lane++ {
+		j := lane * (memory / threads)
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			blake2bHash(block0[:], h0[:])
+			for k := range B[j+i] {
+				B[j+i][k] = binary.LittleEndian.Uint64(block0[k*8:])
+			}
+		}
+	}
+
+	return B
+}
+
+func processBlocks(mode Mode, B []block, time, memory, threads uint32) {
+	laneLength := memory / threads
+	segmentLength := laneLength / syncPoints
+
+	for pass := uint32(0); pass < time; pass++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			for lane := uint32(0); lane < threads; lane++ {
+				var addresses, in, zero block
+				dataIndependent := mode == ModeI || (mode == ModeID && pass == 0 && slice < syncPoints/2)
+				if dataIndependent {
+					in[0] = uint64(pass)
+					in[1] = uint64(lane)
+					in[2] = uint64(slice)
+					in[3] = uint64(memory)
+					in[4] = uint64(time)
+					in[5] = uint64(mode)
+				}
+
+				index := uint32(0)
+				if pass == 0 && slice == 0 {
+					// The first two blocks of every lane are already initialized
+					index = 2
+					if dataIndependent {
+						in[6]++
+						processBlock(&addresses, &in, &zero, false)
+						processBlock(&addresses, &addresses, &zero, false)
+					}
+				}
+
+				offset := lane*laneLength + slice*segmentLength + index
+				for ; index < segmentLength; index, offset = index+1, offset+1 {
+					prev := offset - 1
+					if index == 0 && slice == 0 {
+						prev += laneLength
+					}
+
+					var random uint64
+					if dataIndependent {
+						if index%blockLength == 0 {
+							in[6]++
+							processBlock(&addresses, &in, &zero, false)
+							processBlock(&addresses, &addresses, &zero, false)
+						}
+						random = addresses[index%blockLength]
+					} else {
+						random = B[prev][0]
+					}
+
+					ref := indexAlpha(random, laneLength, segmentLength, threads, pass, slice, lane, index)
+					processBlock(&B[offset], &B[prev], &B[ref], true)
+				}
+			}
+		}
+	}
+}
+
+func indexAlpha(random uint64, laneLength, segmentLength, threads, pass, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % threads
+	if pass == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	m, s := 3*segmentLength, ((slice+1)%syncPoints)*segmentLength
+	if lane == refLane {
+		m += index
+	}
+	if pass == 0 {
+		m, s = slice*segmentLength, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+
+	p := random & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * uint64(m)) >> 32
+	return refLane*laneLength + uint32((uint64(s)+uint64(m)-(p+1))%uint64(laneLength))
+}
+
+func extractKey(B []block, memory, threads, keyLen uint32) []byte {
+	laneLength := memory / threads
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range B[lane*laneLength+laneLength-1] {
+			B[memory-1][i] ^= v
+		}
+	}
+
+	var final [1024]byte
+	for i, v := range B[memory-1] {
+		binary.LittleEndian.PutUint64(final[i*8:], v)
+	}
+
+	key := make([]byte, keyLen)
+	blake2bHash(key, final[:])
+	return key
+}
+
+// blake2bHash is the variable length hash function H' of RFC 9106
+func blake2bHash(out []byte, in []byte) {
+	var b2 hash.Hash
+	if n := len(out); n < blake2b.Size {
+		b2, _ = blake2b.New(n, nil)
+	} else {
+		b2, _ = blake2b.New512(nil)
+	}
+
+	var buffer [blake2b.Size]byte
+	binary.LittleEndian.PutUint32(buffer[:4], uint32(len(out)))
+	b2.Write(buffer[:4])
+	b2.Write(in)
+
+	if len(out) <= blake2b.Size {
+		b2.Sum(out[:0])
+		return
+	}
+
+	outLen := len(out)
+	b2.Sum(buffer[:0])
+	b2.Reset()
+	copy(out, buffer[:32])
+	out = out[32:]
+	for len(out) > blake2b.Size {
+		b2.Write(buffer[:])
+		b2.Sum(buffer[:0])
+		copy(out, buffer[:32])
+		out = out[32:]
+		b2.Reset()
+	}
+
+	if outLen%blake2b.Size > 0 {
+		r := ((outLen + 31) / 32) - 2
+		b2, _ = blake2b.New(outLen-32*r, nil)
+	}
+	b2.Write(buffer[:])
+	b2.Sum(out[:0])
+}
+
+// processBlock computes the compression function G of in1 and in2 into out,
+// XORed with the previous content of out if xor is set
+func processBlock(out, in1, in2 *block, xor bool) {
+	var t block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+
+	for i := 0; i < blockLength; i += 16 {
+		blamka(&t, i, i+1, i+2, i+3, i+4, i+5, i+6, i+7, i+8, i+9, i+10, i+11, i+12, i+13, i+14, i+15)
+	}
+	for i := 0; i < blockLength/8; i += 2 {
+		blamka(&t, i, i+1, 16+i, 16+i+1, 32+i, 32+i+1, 48+i, 48+i+1, 64+i, 64+i+1, 80+i, 80+i+1, 96+i, 96+i+1, 112+i, 112+i+1)
+	}
+
+	for i := range t {
+		if xor {
+			out[i] ^= in1[i] ^ in2[i] ^ t[i]
+		} else {
+			out[i] = in1[i] ^ in2[i] ^ t[i]
+		}
+	}
+}
+
+func blamka(t *block, i ...int) {
+	g := func(a, b, c, d int) {
+		t[a] += t[b] + 2*uint64(uint32(t[a]))*uint64(uint32(t[b]))
+		t[d] ^= t[a]
+		t[d] = t[d]>>32 | t[d]<<32
+		t[c] += t[d] + 2*uint64(uint32(t[c]))*uint64(uint32(t[d]))
+		t[b] ^= t[c]
+		t[b] = t[b]>>24 | t[b]<<40
+		t[a] += t[b] + 2*uint64(uint32(t[a]))*uint64(uint32(t[b]))
+		t[d] ^= t[a]
+		t[d] = t[d]>>16 | t[d]<<48
+		t[c] += t[d] + 2*uint64(uint32(t[c]))*uint64(uint32(t[d]))
+		t[b] ^= t[c]
+		t[b] = t[b]>>63 | t[b]<<1
+	}
+
+	g(i[0], i[4], i[8], i[12])
+	g(i[1], i[5], i[9], i[13])
+	g(i[2], i[6], i[10], i[14])
+	g(i[3], i[7], i[11], i[15])
+	g(i[0], i[5], i[10], i[15])
+	g(i[1], i[6], i[11], i[12])
+	g(i[2], i[7], i[8], i[13])
+	g(i[3], i[4], i[9], i[14])
+}
@@ -0,0 +1,51 @@
+package argon2
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	xargon2 "golang.org/x/crypto/argon2"
+)
+
+// Test vectors from RFC 9106 section 5
+func TestRFC9106(t *testing.T) {
+	password := make([]byte, 32)
+	salt := make([]byte, 16)
+	secret := make([]byte, 8)
+	data := make([]byte, 12)
+	for i := range password {
+		password[i] = 0x01
+	}
+	for i := range salt {
+		salt[i] = 0x02
+	}
+	for i := range secret {
+		secret[i] = 0x03
+	}
+	for i := range data {
+		data[i] = 0x04
+	}
+
+	tests := []struct {
+		mode Mode
+		want string
+	}{
+		{ModeD, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{ModeI, "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{ModeID, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	}
+
+	for _, tt := range tests {
+		got := Key(tt.mode, password, salt, secret, data, 3, 32, 4, 32)
+		assert.Equal(t, tt.want, hex.EncodeToString(got), "mode %d", tt.mode)
+	}
+}
+
+func TestMatchesXCrypto(t *testing.T) {
+	password := []byte("password")
+	salt := []byte("somesalt")
+
+	assert.Equal(t, xargon2.IDKey(password, salt, 2, 256, 2, 32), Key(ModeID, password, salt, nil, nil, 2, 256, 2, 32))
+	assert.Equal(t, xargon2.Key(password, salt, 2, 256, 2, 64), Key(ModeI, password, salt, nil, nil, 2, 256, 2, 64))
+}
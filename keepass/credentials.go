@@ -0,0 +1,76 @@
+package keepass
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// Credentials unlock a database with a master password, a key file or both
+type Credentials struct {
+	Password string
+	KeyFile  []byte // Raw content of the key file
+}
+
+// compositeKey hashes all present key components as KeePass does
+func (c Credentials) compositeKey() ([]byte, error) {
+	if c.Password == "" && c.KeyFile == nil {
+		return nil, errors.New("keepass: password or key file required")
+	}
+
+	composite := sha256.New()
+	if c.Password != "" {
+		hash := sha256.Sum256([]byte(c.Password))
+		composite.Write(hash[:])
+	}
+	if c.KeyFile != nil {
+		key, err := keyFileKey(c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		composite.Write(key)
+	}
+
+	return composite.Sum(nil), nil
+}
+
+// keyFileKey extracts the 32 byte key from the supported key file formats:
+// XML version 1.0 and 2.0, raw 32 bytes, 64 hex characters or any other file
+// which is hashed as a whole
+func keyFileKey(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<KeyFile")) {
+		var keyFile struct {
+			Meta struct {
+				Version string `xml:"Version"`
+			} `xml:"Meta"`
+			Key struct {
+				Data string `xml:"Data"`
+			} `xml:"Key"`
+		}
+		if err := xml.Unmarshal(trimmed, &keyFile); err == nil && keyFile.Key.Data != "" {
+			content := strings.Join(strings.Fields(keyFile.Key.Data), "")
+			if strings.HasPrefix(keyFile.Meta.Version, "2.") {
+				return hex.DecodeString(content)
+			}
+			return base64.StdEncoding.DecodeString(content)
+		}
+	}
+
+	if len(data) == 32 {
+		return data, nil
+	}
+	if len(data) == 64 {
+		if key, err := hex.DecodeString(string(data)); err == nil {
+			return key, nil
+		}
+	}
+
+	hash := sha256.Sum256(data)
+	return hash[:], nil
+}
@@ -0,0 +1,210 @@
+package keepass
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"slices"
+)
+
+// VariantDictionary value types
+const (
+	variantUInt32    = 0x04
+	variantUInt64    = 0x05
+	variantBool      = 0x08
+	variantInt32     = 0x0C
+	variantInt64     = 0x0D
+	variantString    = 0x18
+	variantByteArray = 0x42
+)
+
+func readVariantDictionary(data []byte) (map[string]any, error) {
+	if len(data) < 2 || data[1] != 0x01 {
+		return nil, ErrCorrupted
+	}
+	data = data[2:]
+
+	result := make(map[string]any)
+	for {
+		if len(data) < 1 {
+			return nil, ErrCorrupted
+		}
+		kind := data[0]
+		data = data[1:]
+		if kind == 0 {
+			return result, nil
+		}
+
+		var name, value []byte
+		for _, field := range []*[]byte{&name, &value} {
+			if len(data) < 4 {
+				return nil, ErrCorrupted
+			}
+			size := int(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+			if size < 0 || size > len(data) {
+				return nil, ErrCorrupted
+			}
+			*field = data[:size]
+			data = data[size:]
+		}
+
+		switch kind {
+		case variantUInt32:
+			if len(value) != 4 {
+				return nil, ErrCorrupted
+			}
+			result[string(name)] = binary.LittleEndian.Uint32(value)
+		case variantUInt64:
+			if len(value) != 8 {
+				return nil, ErrCorrupted
+			}
+			result[string(name)] = binary.LittleEndian.Uint64(value)
+		case variantBool:
+			result[string(name)] = len(value) == 1 && value[0] != 0
+		case variantInt32:
+			if len(value) != 4 {
+				return nil, ErrCorrupted
+			}
+			result[string(name)] = int32(binary.LittleEndian.Uint32(value))
+		case variantInt64:
+			if len(value) != 8 {
+				return nil, ErrCorrupted
+			}
+			result[string(name)] = int64(binary.LittleEndian.Uint64(value))
+		case variantString:
+			result[string(name)] = string(value)
+		case variantByteArray:
+			result[string(name)] = bytes.Clone(value)
+		}
+	}
+}
+
+func writeVariantDictionary(values map[string]any) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x00, 0x01})
+
+	// Sorted for a deterministic header
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		var kind byte
+		var value []byte
+		switch v := values[name].(type) {
+		case uint32:
+			kind, value = variantUInt32, binary.LittleEndian.AppendUint32(nil, v)
+		case uint64:
+			kind, value = variantUInt64, binary.LittleEndian.AppendUint64(nil, v)
+		case bool:
+			kind, value = variantBool, []byte{0}
+			if v {
+				value[0] = 1
+			}
+		case int32:
+			kind, value = variantInt32, binary.LittleEndian.AppendUint32(nil, uint32(v))
+		case int64:
+			kind, value = variantInt64, binary.LittleEndian.AppendUint64(nil, uint64(v))
+		case string:
+			kind, value = variantString, []byte(v)
+		case []byte:
+			kind, value = variantByteArray, v
+		default:
+			continue
+		}
+
+		buf.WriteByte(kind)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(name)))
+		buf.WriteString(name)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(value)))
+		buf.Write(value)
+	}
+
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// transformProtected rewrites the content of all protected values in document
+// order. When decoding, base64 ciphertext is replaced by plain text, when
+// encoding the reverse happens. The stream must be fresh for every document.
+func transformProtected(document []byte, stream cipher.Stream, encode bool) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+
+	protected := false
+	var content []byte
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Value" {
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "Protected" && (attr.Value == "True" || attr.Value == "true") {
+						protected = true
+						content = content[:0]
+					}
+				}
+			}
+		case xml.CharData:
+			if protected {
+				content = append(content, t...)
+				continue
+			}
+		case xml.EndElement:
+			if protected && t.Name.Local == "Value" {
+				protected = false
+				value, err := protectValue(content, stream, encode)
+				if err != nil {
+					return nil, err
+				}
+				if err := encoder.EncodeToken(xml.CharData(value)); err != nil {
+					return nil, err
+				}
+			}
+		case xml.ProcInst:
+			// The XML declaration is written by callers when needed
+			continue
+		}
+
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	if encode {
+		return append([]byte(xml.Header), out.Bytes()...), nil
+	}
+	return out.Bytes(), nil
+}
+
+func protectValue(content []byte, stream cipher.Stream, encode bool) ([]byte, error) {
+	if encode {
+		value := bytes.Clone(content)
+		stream.XORKeyStream(value, value)
+		return []byte(base64.StdEncoding.EncodeToString(value)), nil
+	}
+
+	value, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(content)))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	stream.XORKeyStream(value, value)
+	return value, nil
+}
@@ -0,0 +1,553 @@
+// Package keepass reads and writes KeePass KDBX 4 databases and mirrors them
+// to and from Passwork vaults.
+package keepass
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+
+	"github.com/treasure33/passwork-client-go/internal/argon2"
+)
+
+const (
+	signature1 = 0x9AA2D903
+	signature2 = 0xB54BFB67
+	version4   = 0x00040000
+
+	// Outer header field IDs
+	headerEnd         = 0
+	headerCipherID    = 2
+	headerCompression = 3
+	headerMasterSeed  = 4
+	headerEncryptIV   = 7
+	headerKdfParams   = 11
+
+	// Inner header field IDs
+	innerEnd       = 0
+	innerStreamID  = 1
+	innerStreamKey = 2
+	innerBinary    = 3
+
+	streamSalsa20  = 2
+	streamChaCha20 = 3
+
+	hmacBlockSize = 1024 * 1024
+
+	// Limits on KDF parameters read from a file, above what KeePass and
+	// KeePassXC choose for several seconds of unlock time, so a crafted file
+	// can't exhaust memory or CPU
+	maxArgon2Memory     = 1024 * 1024 * 1024
+	maxArgon2Iterations = 1000
+	maxAESRounds        = 100_000_000
+
+	// Limit on the decompressed payload, so a crafted file can't inflate
+	// without bound
+	maxPayloadSize = 256 * 1024 * 1024
+)
+
+var (
+	cipherAES256  = [16]byte{0x31, 0xc1, 0xf2, 0xe6, 0xbf, 0x71, 0x43, 0x50, 0xbe, 0x58, 0x05, 0x21, 0x6a, 0xfc, 0x5a, 0xff}
+	cipherChaCha  = [16]byte{0xd6, 0x03, 0x8a, 0x2b, 0x8b, 0x6f, 0x4c, 0xb5, 0xa5, 0x24, 0x33, 0x9a, 0x31, 0xdb, 0xb5, 0x9a}
+	kdfAES        = [16]byte{0xc9, 0xd9, 0xf3, 0x9a, 0x62, 0x8a, 0x44, 0x60, 0xbf, 0x74, 0x0d, 0x08, 0xc1, 0x8a, 0x4f, 0xea}
+	kdfArgon2d    = [16]byte{0xef, 0x63, 0x6d, 0xdf, 0x8c, 0x29, 0x44, 0x4b, 0x91, 0xf7, 0xa9, 0xa4, 0x03, 0xe3, 0x0a, 0x0c}
+	kdfArgon2id   = [16]byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+	salsa20Nonce  = []byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A}
+	headerHMACIdx = uint64(math.MaxUint64)
+)
+
+var (
+	ErrInvalidFile        = errors.New("keepass: not a KDBX file")
+	ErrUnsupportedVersion = errors.New("keepass: only KDBX 4 is supported")
+	ErrInvalidCredentials = errors.New("keepass: wrong password or key file")
+	ErrCorrupted          = errors.New("keepass: file is corrupted")
+	ErrKDFLimit           = errors.New("keepass: key derivation parameters exceed limits")
+	ErrPayloadLimit       = errors.New("keepass: decompressed database exceeds size limit")
+)
+
+type KDF int
+
+const (
+	KDFArgon2d KDF = iota
+	KDFArgon2id
+	KDFAES
+)
+
+type WriteOptions struct {
+	// KDF used to transform the master key, defaults to KDFArgon2d like KeePass
+	KDF KDF
+
+	// Iterations of the KDF, defaults to 2 for Argon2 and 600000 for AES-KDF
+	Iterations uint64
+
+	// Memory in bytes used by Argon2, defaults to 64 MiB
+	Memory uint64
+
+	// Parallelism of Argon2, defaults to 2
+	Parallelism uint32
+}
+
+// Open decrypts and parses a KDBX 4 database
+func Open(r io.Reader, credentials Credentials) (*Database, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || binary.LittleEndian.Uint32(data[0:]) != signature1 || binary.LittleEndian.Uint32(data[4:]) != signature2 {
+		return nil, ErrInvalidFile
+	}
+	if binary.LittleEndian.Uint32(data[8:])>>16 != 4 {
+		return nil, ErrUnsupportedVersion
+	}
+
+	// Outer header
+	fields := make(map[byte][]byte)
+	offset := 12
+	for {
+		if offset+5 > len(data) {
+			return nil, ErrCorrupted
+		}
+		id := data[offset]
+		size := int(binary.LittleEndian.Uint32(data[offset+1:]))
+		offset += 5
+		if size < 0 || offset+size > len(data) {
+			return nil, ErrCorrupted
+		}
+		fields[id] = data[offset : offset+size]
+		offset += size
+		if id == headerEnd {
+			break
+		}
+	}
+	header := data[:offset]
+	if offset+64 > len(data) {
+		return nil, ErrCorrupted
+	}
+	headerHash := sha256.Sum256(header)
+	if !hmac.Equal(headerHash[:], data[offset:offset+32]) {
+		return nil, ErrCorrupted
+	}
+
+	composite, err := credentials.compositeKey()
+	if err != nil {
+		return nil, err
+	}
+	kdfParams, err := readVariantDictionary(fields[headerKdfParams])
+	if err != nil {
+		return nil, err
+	}
+	transformed, err := transformKey(composite, kdfParams)
+	if err != nil {
+		return nil, err
+	}
+
+	masterSeed := fields[headerMasterSeed]
+	if len(masterSeed) != 32 {
+		return nil, ErrCorrupted
+	}
+	encryptionKey, hmacKey := deriveKeys(masterSeed, transformed)
+
+	if !hmac.Equal(headerHMAC(hmacKey, header), data[offset+32:offset+64]) {
+		return nil, ErrInvalidCredentials
+	}
+
+	ciphertext, err := readHMACBlocks(data[offset+64:], hmacKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var cipherID [16]byte
+	copy(cipherID[:], fields[headerCipherID])
+	plaintext, err := decryptPayload(cipherID, encryptionKey, fields[headerEncryptIV], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if compression := fields[headerCompression]; len(compression) == 4 && binary.LittleEndian.Uint32(compression) == 1 {
+		gz, err := gzip.NewReader(bytes.NewReader(plaintext))
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+		if plaintext, err = io.ReadAll(io.LimitReader(gz, maxPayloadSize+1)); err != nil {
+			return nil, ErrCorrupted
+		}
+		if len(plaintext) > maxPayloadSize {
+			return nil, ErrPayloadLimit
+		}
+	}
+
+	// Inner header
+	db := &Database{}
+	var streamID uint32
+	var streamKey []byte
+	offset = 0
+	for {
+		if offset+5 > len(plaintext) {
+			return nil, ErrCorrupted
+		}
+		id := plaintext[offset]
+		size := int(binary.LittleEndian.Uint32(plaintext[offset+1:]))
+		offset += 5
+		if size < 0 || offset+size > len(plaintext) {
+			return nil, ErrCorrupted
+		}
+		value := plaintext[offset : offset+size]
+		offset += size
+
+		switch id {
+		case innerStreamID:
+			if len(value) != 4 {
+				return nil, ErrCorrupted
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerStreamKey:
+			streamKey = value
+		case innerBinary:
+			if len(value) < 1 {
+				return nil, ErrCorrupted
+			}
+			db.Binaries = append(db.Binaries, bytes.Clone(value[1:]))
+		}
+		if id == innerEnd {
+			break
+		}
+	}
+
+	stream, err := newInnerStream(streamID, streamKey)
+	if err != nil {
+		return nil, err
+	}
+	document, err := transformProtected(plaintext[offset:], stream, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := xml.Unmarshal(document, db); err != nil {
+		return nil, fmt.Errorf("keepass: parse XML: %w", err)
+	}
+
+	return db, nil
+}
+
+// Write encrypts the database as KDBX 4 using AES-256 and gzip compression
+func (db *Database) Write(w io.Writer, credentials Credentials, opts WriteOptions) error {
+	composite, err := credentials.compositeKey()
+	if err != nil {
+		return err
+	}
+
+	masterSeed := randomBytes(32)
+	iv := randomBytes(16)
+	kdfParams := newKdfParams(opts)
+
+	var header bytes.Buffer
+	binary.Write(&header, binary.LittleEndian, uint32(signature1))
+	binary.Write(&header, binary.LittleEndian, uint32(signature2))
+	binary.Write(&header, binary.LittleEndian, uint32(version4))
+	writeOuterField(&header, headerCipherID, cipherAES256[:])
+	writeOuterField(&header, headerCompression, binary.LittleEndian.AppendUint32(nil, 1))
+	writeOuterField(&header, headerMasterSeed, masterSeed)
+	writeOuterField(&header, headerEncryptIV, iv)
+	writeOuterField(&header, headerKdfParams, writeVariantDictionary(kdfParams))
+	writeOuterField(&header, headerEnd, []byte("\r\n\r\n"))
+
+	transformed, err := transformKey(composite, kdfParams)
+	if err != nil {
+		return err
+	}
+	encryptionKey, hmacKey := deriveKeys(masterSeed, transformed)
+
+	// Inner header and XML document
+	streamKey := randomBytes(64)
+	var inner bytes.Buffer
+	writeInnerField(&inner, innerStreamID, binary.LittleEndian.AppendUint32(nil, streamChaCha20))
+	writeInnerField(&inner, innerStreamKey, streamKey)
+	for _, binaryData := range db.Binaries {
+		writeInnerField(&inner, innerBinary, append([]byte{0x01}, binaryData...))
+	}
+	writeInnerField(&inner, innerEnd, nil)
+
+	document, err := xml.MarshalIndent(db, "", "\t")
+	if err != nil {
+		return err
+	}
+	stream, err := newInnerStream(streamChaCha20, streamKey)
+	if err != nil {
+		return err
+	}
+	document, err = transformProtected(document, stream, true)
+	if err != nil {
+		return err
+	}
+	inner.Write(document)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(inner.Bytes())
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	ciphertext, err := encryptAES(encryptionKey, iv, compressed.Bytes())
+	if err != nil {
+		return err
+	}
+
+	headerHash := sha256.Sum256(header.Bytes())
+	out := bytes.NewBuffer(header.Bytes())
+	out.Write(headerHash[:])
+	out.Write(headerHMAC(hmacKey, header.Bytes()))
+	writeHMACBlocks(out, hmacKey, ciphertext)
+
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+func deriveKeys(masterSeed, transformed []byte) ([]byte, []byte) {
+	encryptionKey := sha256.Sum256(append(bytes.Clone(masterSeed), transformed...))
+	hmacKey := sha512.Sum512(append(append(bytes.Clone(masterSeed), transformed...), 0x01))
+	return encryptionKey[:], hmacKey[:]
+}
+
+func blockHMACKey(hmacKey []byte, index uint64) []byte {
+	key := sha512.Sum512(append(binary.LittleEndian.AppendUint64(nil, index), hmacKey...))
+	return key[:]
+}
+
+func headerHMAC(hmacKey, header []byte) []byte {
+	mac := hmac.New(sha256.New, blockHMACKey(hmacKey, headerHMACIdx))
+	mac.Write(header)
+	return mac.Sum(nil)
+}
+
+func blockHMAC(hmacKey []byte, index uint64, block []byte) []byte {
+	mac := hmac.New(sha256.New, blockHMACKey(hmacKey, index))
+	mac.Write(binary.LittleEndian.AppendUint64(nil, index))
+	mac.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(block))))
+	mac.Write(block)
+	return mac.Sum(nil)
+}
+
+func readHMACBlocks(data []byte, hmacKey []byte) ([]byte, error) {
+	var result []byte
+	for index := uint64(0); ; index++ {
+		if len(data) < 36 {
+			return nil, ErrCorrupted
+		}
+		mac := data[:32]
+		size := int(binary.LittleEndian.Uint32(data[32:]))
+		data = data[36:]
+		if size < 0 || size > len(data) {
+			return nil, ErrCorrupted
+		}
+		block := data[:size]
+		data = data[size:]
+
+		if !hmac.Equal(mac, blockHMAC(hmacKey, index, block)) {
+			return nil, ErrCorrupted
+		}
+		if size == 0 {
+			return result, nil
+		}
+		result = append(result, block...)
+	}
+}
+
+func writeHMACBlocks(w *bytes.Buffer, hmacKey []byte, data []byte) {
+	for index := uint64(0); ; index++ {
+		size := min(len(data), hmacBlockSize)
+		block := data[:size]
+		data = data[size:]
+
+		w.Write(blockHMAC(hmacKey, index, block))
+		binary.Write(w, binary.LittleEndian, uint32(size))
+		w.Write(block)
+
+		if size == 0 {
+			return
+		}
+	}
+}
+
+func decryptPayload(cipherID [16]byte, key, iv, ciphertext []byte) ([]byte, error) {
+	switch cipherID {
+	case cipherAES256:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, ErrCorrupted
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+		padding := int(plaintext[len(plaintext)-1])
+		if padding == 0 || padding > aes.BlockSize || padding > len(plaintext) {
+			return nil, ErrCorrupted
+		}
+		return plaintext[:len(plaintext)-padding], nil
+	case cipherChaCha:
+		stream, err := chacha20.NewUnauthenticatedCipher(key, iv)
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+		plaintext := make([]byte, len(ciphertext))
+		stream.XORKeyStream(plaintext, ciphertext)
+		return plaintext, nil
+	default:
+		return nil, errors.New("keepass: unsupported cipher")
+	}
+}
+
+func encryptAES(key, iv, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return padded, nil
+}
+
+// newInnerStream returns the cipher stream protecting values inside the XML
+func newInnerStream(id uint32, key []byte) (cipher.Stream, error) {
+	switch id {
+	case streamChaCha20:
+		hash := sha512.Sum512(key)
+		return chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+	case streamSalsa20:
+		hash := sha256.Sum256(key)
+		return &salsaStream{key: hash}, nil
+	default:
+		return nil, fmt.Errorf("keepass: unsupported inner stream %d", id)
+	}
+}
+
+// salsaStream adapts the Salsa20 keystream to cipher.Stream
+type salsaStream struct {
+	key       [32]byte
+	block     uint64
+	keystream []byte
+}
+
+func (s *salsaStream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if len(s.keystream) == 0 {
+			var counter [16]byte
+			copy(counter[:], salsa20Nonce)
+			binary.LittleEndian.PutUint64(counter[8:], s.block)
+			s.keystream = make([]byte, 64)
+			salsa.XORKeyStream(s.keystream, s.keystream, &counter, &s.key)
+			s.block++
+		}
+		dst[i] = src[i] ^ s.keystream[0]
+		s.keystream = s.keystream[1:]
+	}
+}
+
+func transformKey(composite []byte, params map[string]any) ([]byte, error) {
+	uuid, _ := params["$UUID"].([]byte)
+	var id [16]byte
+	copy(id[:], uuid)
+
+	switch id {
+	case kdfAES:
+		seed, _ := params["S"].([]byte)
+		rounds, _ := params["R"].(uint64)
+		if rounds > maxAESRounds {
+			return nil, ErrKDFLimit
+		}
+		block, err := aes.NewCipher(seed)
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+		key := bytes.Clone(composite)
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(key[:16], key[:16])
+			block.Encrypt(key[16:], key[16:])
+		}
+		hash := sha256.Sum256(key)
+		return hash[:], nil
+	case kdfArgon2d, kdfArgon2id:
+		salt, _ := params["S"].([]byte)
+		iterations, _ := params["I"].(uint64)
+		memory, _ := params["M"].(uint64)
+		parallelism, _ := params["P"].(uint32)
+		if iterations == 0 || parallelism == 0 || parallelism > 255 || memory < 1024 {
+			return nil, ErrCorrupted
+		}
+		if iterations > maxArgon2Iterations || memory > maxArgon2Memory {
+			return nil, ErrKDFLimit
+		}
+		mode := argon2.ModeD
+		if id == kdfArgon2id {
+			mode = argon2.ModeID
+		}
+		return argon2.Key(mode, composite, salt, nil, nil, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+	default:
+		return nil, errors.New("keepass: unsupported key derivation function")
+	}
+}
+
+func newKdfParams(opts WriteOptions) map[string]any {
+	if opts.KDF == KDFAES {
+		rounds := opts.Iterations
+		if rounds == 0 {
+			rounds = 600000
+		}
+		return map[string]any{"$UUID": kdfAES[:], "S": randomBytes(32), "R": rounds}
+	}
+
+	uuid := kdfArgon2d
+	if opts.KDF == KDFArgon2id {
+		uuid = kdfArgon2id
+	}
+	params := map[string]any{
+		"$UUID": uuid[:],
+		"S":     randomBytes(32),
+		"I":     opts.Iterations,
+		"M":     opts.Memory,
+		"P":     opts.Parallelism,
+		"V":     uint32(argon2.Version),
+	}
+	if opts.Iterations == 0 {
+		params["I"] = uint64(2)
+	}
+	if opts.Memory == 0 {
+		params["M"] = uint64(64 * 1024 * 1024)
+	}
+	if opts.Parallelism == 0 {
+		params["P"] = uint32(2)
+	}
+	return params
+}
+
+func writeOuterField(w *bytes.Buffer, id byte, data []byte) {
+	w.WriteByte(id)
+	binary.Write(w, binary.LittleEndian, uint32(len(data)))
+	w.Write(data)
+}
+
+func writeInnerField(w *bytes.Buffer, id byte, data []byte) {
+	writeOuterField(w, id, data)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
@@ -0,0 +1,240 @@
+package keepass
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+// Cheap KDF parameters to keep tests fast
+var testOptions = WriteOptions{Iterations: 1, Memory: 64 * 1024, Parallelism: 1}
+
+func testDatabase() *Database {
+	manifest := passwork.ArchiveManifest{
+		Vault: passwork.ArchiveVault{Name: "vault"},
+		Folders: []passwork.ArchiveFolder{
+			{Id: "f1", Name: "Servers", Path: []string{"Servers"}},
+			{Id: "f2", ParentId: "f1", Name: "Databases", Path: []string{"Servers", "Databases"}},
+		},
+		Items: []passwork.ArchiveItem{
+			{
+				Id:          "i1",
+				Name:        "root item",
+				Login:       "jo",
+				Password:    passwork.NewSecret("s3cret & <more>"),
+				Url:         "https://example.com",
+				Description: "notes",
+				Tags:        []string{"a", "b"},
+			},
+			{
+				Id:       "i2",
+				FolderId: "f2",
+				Name:     "postgres",
+				Login:    "admin",
+				Password: passwork.NewSecret("pg-pass"),
+				Custom: []passwork.PasswordCustomData{
+					{Name: "port", Value: passwork.NewSecret("5432"), Type: "text"},
+					{Name: "token", Value: passwork.NewSecret("tok"), Type: "password"},
+				},
+				Attachments: []passwork.ArchiveAttachment{{Id: "a1", Name: "ca.pem", File: "attachments/i2/a1"}},
+			},
+		},
+	}
+	files := map[string][]byte{"attachments/i2/a1": []byte("-----BEGIN CERTIFICATE-----")}
+
+	return FromManifest(manifest, files)
+}
+
+func TestWriteOpen(t *testing.T) {
+	credentials := []struct {
+		name        string
+		credentials Credentials
+	}{
+		{"password", Credentials{Password: "master"}},
+		{"key file", Credentials{KeyFile: []byte("any key file content")}},
+		{"both", Credentials{Password: "master", KeyFile: bytes.Repeat([]byte{7}, 32)}},
+	}
+	kdfs := []struct {
+		name string
+		kdf  KDF
+	}{
+		{"argon2d", KDFArgon2d},
+		{"argon2id", KDFArgon2id},
+		{"aes", KDFAES},
+	}
+
+	for _, c := range credentials {
+		for _, k := range kdfs {
+			t.Run(c.name+"/"+k.name, func(t *testing.T) {
+				opts := testOptions
+				opts.KDF = k.kdf
+				if k.kdf == KDFAES {
+					opts.Iterations = 1000
+				}
+
+				var buf bytes.Buffer
+				require.NoError(t, testDatabase().Write(&buf, c.credentials, opts))
+
+				db, err := Open(bytes.NewReader(buf.Bytes()), c.credentials)
+				require.NoError(t, err)
+
+				entry := db.Root.Group.Entries[0]
+				assert.Equal(t, "s3cret & <more>", entry.Get(KeyPassword))
+				assert.True(t, bool(entry.Strings[2].Value.Protected), "Password should stay protected.")
+
+				nested := db.Root.Group.Groups[0].Groups[0].Entries[0]
+				assert.Equal(t, "pg-pass", nested.Get(KeyPassword))
+				assert.Equal(t, "tok", nested.Get("token"))
+				assert.Equal(t, []byte("-----BEGIN CERTIFICATE-----"), db.Binaries[nested.Binaries[0].Value.Ref])
+			})
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testDatabase().Write(&buf, Credentials{Password: "master"}, testOptions))
+	data := buf.Bytes()
+
+	_, err := Open(bytes.NewReader(data), Credentials{Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-100] ^= 1
+	_, err = Open(bytes.NewReader(tampered), Credentials{Password: "master"})
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	_, err = Open(bytes.NewReader([]byte("not a database")), Credentials{Password: "master"})
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestOpenExternal(t *testing.T) {
+	data, err := os.ReadFile("testdata/external.kdbx")
+	require.NoError(t, err)
+
+	db, err := Open(bytes.NewReader(data), Credentials{Password: "passwork"})
+	require.NoError(t, err)
+	assert.Equal(t, "external.js", db.Meta.Generator)
+	assert.Equal(t, "Passwork", db.Meta.DatabaseName)
+
+	manifest, files := ToManifest(db)
+	require.Len(t, manifest.Folders, 1)
+	assert.Equal(t, []string{"Servers"}, manifest.Folders[0].Path)
+	require.Len(t, manifest.Items, 1)
+	item := manifest.Items[0]
+	assert.Equal(t, manifest.Folders[0].Id, item.FolderId)
+	assert.Equal(t, "postgres", item.Name)
+	assert.Equal(t, "admin", item.Login)
+	assert.Equal(t, "s3cret", item.Password.Reveal())
+	assert.Equal(t, "https://db.example.com", item.Url)
+	assert.Equal(t, "primary", item.Description)
+	assert.Equal(t, []string{"db", "prod"}, item.Tags)
+	assert.Equal(t, []passwork.PasswordCustomData{{Name: "port", Value: passwork.NewSecret("5432"), Type: "text"}}, item.Custom)
+	require.Len(t, item.Attachments, 1)
+	assert.Equal(t, "ca.pem", item.Attachments[0].Name)
+	assert.Equal(t, []byte("-----BEGIN CERTIFICATE-----"), files[item.Attachments[0].File])
+
+	_, err = Open(bytes.NewReader(data), Credentials{Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestKDFLimits(t *testing.T) {
+	params := func(iterations, memory uint64) map[string]any {
+		return map[string]any{"$UUID": kdfArgon2d[:], "S": make([]byte, 32), "I": iterations, "M": memory, "P": uint32(1)}
+	}
+
+	_, err := transformKey(make([]byte, 32), params(maxArgon2Iterations+1, 64*1024))
+	assert.ErrorIs(t, err, ErrKDFLimit)
+	_, err = transformKey(make([]byte, 32), params(1, maxArgon2Memory+1024))
+	assert.ErrorIs(t, err, ErrKDFLimit)
+	_, err = transformKey(make([]byte, 32), params(1, 64*1024))
+	assert.NoError(t, err)
+
+	aesParams := map[string]any{"$UUID": kdfAES[:], "S": make([]byte, 32), "R": uint64(maxAESRounds + 1)}
+	_, err = transformKey(make([]byte, 32), aesParams)
+	assert.ErrorIs(t, err, ErrKDFLimit)
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	manifest, files := ToManifest(testDatabase())
+
+	require.Len(t, manifest.Folders, 2)
+	assert.Equal(t, []string{"Servers", "Databases"}, manifest.Folders[1].Path)
+	assert.Equal(t, manifest.Folders[0].Id, manifest.Folders[1].ParentId)
+
+	require.Len(t, manifest.Items, 2)
+	assert.Equal(t, "", manifest.Items[0].FolderId)
+	assert.Equal(t, []string{"a", "b"}, manifest.Items[0].Tags)
+
+	item := manifest.Items[1]
+	assert.Equal(t, manifest.Folders[1].Id, item.FolderId)
+	assert.Equal(t, []passwork.PasswordCustomData{
+		{Name: "port", Value: passwork.NewSecret("5432"), Type: "text"},
+		{Name: "token", Value: passwork.NewSecret("tok"), Type: "password"},
+	}, item.Custom)
+	require.Len(t, item.Attachments, 1)
+	assert.Equal(t, "ca.pem", item.Attachments[0].Name)
+	assert.Equal(t, []byte("-----BEGIN CERTIFICATE-----"), files[item.Attachments[0].File])
+}
+
+func TestFromManifestUnknownParents(t *testing.T) {
+	manifest := passwork.ArchiveManifest{
+		Folders: []passwork.ArchiveFolder{
+			{Id: "f1", ParentId: "outside", Name: "Servers"},
+			{Id: "f2", ParentId: "f1", Name: "Databases"},
+		},
+		Items: []passwork.ArchiveItem{
+			{Id: "i1", FolderId: "gone", Name: "orphan"},
+			{Id: "i2", FolderId: "f2", Name: "postgres"},
+		},
+	}
+
+	db := FromManifest(manifest, nil)
+
+	root := db.Root.Group
+	require.Len(t, root.Groups, 1)
+	assert.Equal(t, "Servers", root.Groups[0].Name)
+	require.Len(t, root.Groups[0].Groups, 1)
+	assert.Equal(t, "postgres", root.Groups[0].Groups[0].Entries[0].Get(KeyTitle))
+	require.Len(t, root.Entries, 1)
+	assert.Equal(t, "orphan", root.Entries[0].Get(KeyTitle))
+}
+
+func TestRecycleBinSkipped(t *testing.T) {
+	db := testDatabase()
+	bin := Group{UUID: NewUUID(), Name: "Recycle Bin", Entries: []Entry{{UUID: NewUUID()}}}
+	db.Root.Group.Groups = append(db.Root.Group.Groups, bin)
+	db.Meta.RecycleBinEnabled = true
+	db.Meta.RecycleBinUUID = bin.UUID
+
+	manifest, _ := ToManifest(db)
+
+	assert.Len(t, manifest.Folders, 2)
+	assert.Len(t, manifest.Items, 2)
+}
+
+func TestKeyFile(t *testing.T) {
+	xmlV2 := `<?xml version="1.0" encoding="utf-8"?>
+<KeyFile>
+	<Meta><Version>2.0</Version></Meta>
+	<Key><Data Hash="A65F5FA5">
+		0102030405060708 090A0B0C0D0E0F10
+		1112131415161718 191A1B1C1D1E1F20
+	</Data></Key>
+</KeyFile>`
+	key, err := keyFileKey([]byte(xmlV2))
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x01), key[0])
+	assert.Equal(t, byte(0x20), key[31])
+
+	hexKey := "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+	key, err = keyFileKey([]byte(hexKey))
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+	assert.Equal(t, byte(0x20), key[31])
+}
@@ -0,0 +1,197 @@
+package keepass
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Standard entry string keys
+const (
+	KeyTitle    = "Title"
+	KeyUserName = "UserName"
+	KeyPassword = "Password"
+	KeyURL      = "URL"
+	KeyNotes    = "Notes"
+)
+
+// Database is the decrypted content of a KDBX file
+type Database struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    Meta     `xml:"Meta"`
+	Root    Root     `xml:"Root"`
+
+	// Binaries holds the attachment pool referenced by BinaryRef.Value.Ref
+	Binaries [][]byte `xml:"-"`
+}
+
+type Meta struct {
+	Generator         string `xml:"Generator"`
+	DatabaseName      string `xml:"DatabaseName"`
+	RecycleBinEnabled Bool   `xml:"RecycleBinEnabled"`
+	RecycleBinUUID    UUID   `xml:"RecycleBinUUID"`
+}
+
+type Root struct {
+	Group Group `xml:"Group"`
+}
+
+type Group struct {
+	UUID    UUID    `xml:"UUID"`
+	Name    string  `xml:"Name"`
+	Notes   string  `xml:"Notes,omitempty"`
+	Times   Times   `xml:"Times"`
+	Entries []Entry `xml:"Entry"`
+	Groups  []Group `xml:"Group"`
+}
+
+type Entry struct {
+	UUID     UUID        `xml:"UUID"`
+	Times    Times       `xml:"Times"`
+	Tags     string      `xml:"Tags,omitempty"`
+	Strings  []String    `xml:"String"`
+	Binaries []BinaryRef `xml:"Binary"`
+	History  *History    `xml:"History,omitempty"`
+}
+
+type History struct {
+	Entries []Entry `xml:"Entry"`
+}
+
+type String struct {
+	Key   string `xml:"Key"`
+	Value Value  `xml:"Value"`
+}
+
+type Value struct {
+	Protected Bool   `xml:"Protected,attr,omitempty"`
+	Content   string `xml:",chardata"`
+}
+
+type BinaryRef struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Ref int `xml:"Ref,attr"`
+	} `xml:"Value"`
+}
+
+type Times struct {
+	CreationTime         Time `xml:"CreationTime"`
+	LastModificationTime Time `xml:"LastModificationTime"`
+	LastAccessTime       Time `xml:"LastAccessTime"`
+	ExpiryTime           Time `xml:"ExpiryTime"`
+	Expires              Bool `xml:"Expires"`
+	UsageCount           int  `xml:"UsageCount"`
+	LocationChanged      Time `xml:"LocationChanged"`
+}
+
+// NewTimes returns times for an object created now that never expires
+func NewTimes() Times {
+	now := Time(time.Now().UTC().Truncate(time.Second))
+	return Times{
+		CreationTime:         now,
+		LastModificationTime: now,
+		LastAccessTime:       now,
+		ExpiryTime:           now,
+		LocationChanged:      now,
+	}
+}
+
+// Get returns the value of the string field key
+func (e Entry) Get(key string) string {
+	for _, s := range e.Strings {
+		if s.Key == key {
+			return s.Value.Content
+		}
+	}
+	return ""
+}
+
+// Set adds or replaces the string field key
+func (e *Entry) Set(key, value string, protected bool) {
+	for i := range e.Strings {
+		if e.Strings[i].Key == key {
+			e.Strings[i].Value = Value{Protected: Bool(protected), Content: value}
+			return
+		}
+	}
+	e.Strings = append(e.Strings, String{Key: key, Value: Value{Protected: Bool(protected), Content: value}})
+}
+
+// UUID is a KeePass object identifier, encoded as base64 in XML
+type UUID [16]byte
+
+// NewUUID returns a random UUID
+func NewUUID() UUID {
+	var u UUID
+	rand.Read(u[:])
+	return u
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(base64.StdEncoding.EncodeToString(u[:])), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = UUID{}
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(u) {
+		return errors.New("keepass: invalid UUID length")
+	}
+	copy(u[:], decoded)
+	return nil
+}
+
+// Bool is encoded as True/False like KeePass does
+type Bool bool
+
+func (b Bool) MarshalText() ([]byte, error) {
+	if b {
+		return []byte("True"), nil
+	}
+	return []byte("False"), nil
+}
+
+func (b *Bool) UnmarshalText(text []byte) error {
+	*b = Bool(strings.EqualFold(strings.TrimSpace(string(text)), "true"))
+	return nil
+}
+
+// Time is encoded as base64 seconds since year 1 in KDBX 4 and as an
+// ISO 8601 string in older formats
+type Time time.Time
+
+var epoch = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func (t Time) MarshalText() ([]byte, error) {
+	seconds := time.Time(t).Unix() - epoch.Unix()
+	return []byte(base64.StdEncoding.EncodeToString(binary.LittleEndian.AppendUint64(nil, uint64(seconds)))), nil
+}
+
+func (t *Time) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = Time{}
+		return nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(string(text)); err == nil && len(decoded) == 8 {
+		seconds := int64(binary.LittleEndian.Uint64(decoded))
+		*t = Time(time.Unix(seconds+epoch.Unix(), 0).UTC())
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, string(text))
+	if err != nil {
+		return err
+	}
+	*t = Time(parsed)
+	return nil
+}
@@ -0,0 +1,188 @@
+package keepass
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+var standardKeys = map[string]bool{KeyTitle: true, KeyUserName: true, KeyPassword: true, KeyURL: true, KeyNotes: true}
+
+// ToManifest converts a database into the vault snapshot format. Groups below
+// the root group become folders, the recycle bin is left out. Attachment
+// blobs are returned keyed by ArchiveAttachment.File.
+func ToManifest(db *Database) (passwork.ArchiveManifest, map[string][]byte) {
+	manifest := passwork.ArchiveManifest{
+		Version: passwork.ArchiveVersion,
+		Vault:   passwork.ArchiveVault{Name: db.Meta.DatabaseName},
+	}
+	files := make(map[string][]byte)
+
+	var walk func(group Group, folderId string, path []string)
+	walk = func(group Group, folderId string, path []string) {
+		for _, entry := range group.Entries {
+			manifest.Items = append(manifest.Items, archiveItem(db, entry, folderId, files))
+		}
+
+		for _, child := range group.Groups {
+			if db.Meta.RecycleBinEnabled && child.UUID == db.Meta.RecycleBinUUID {
+				continue
+			}
+			childPath := append(append([]string{}, path...), child.Name)
+			childId := hex.EncodeToString(child.UUID[:])
+			manifest.Folders = append(manifest.Folders, passwork.ArchiveFolder{
+				Id:       childId,
+				ParentId: folderId,
+				Name:     child.Name,
+				Path:     childPath,
+			})
+			walk(child, childId, childPath)
+		}
+	}
+	walk(db.Root.Group, "", nil)
+
+	return manifest, files
+}
+
+func archiveItem(db *Database, entry Entry, folderId string, files map[string][]byte) passwork.ArchiveItem {
+	id := hex.EncodeToString(entry.UUID[:])
+	item := passwork.ArchiveItem{
+		Id:          id,
+		FolderId:    folderId,
+		Name:        entry.Get(KeyTitle),
+		Login:       entry.Get(KeyUserName),
+		Password:    passwork.NewSecret(entry.Get(KeyPassword)),
+		Url:         entry.Get(KeyURL),
+		Description: entry.Get(KeyNotes),
+		Tags:        splitTags(entry.Tags),
+	}
+
+	for _, s := range entry.Strings {
+		if standardKeys[s.Key] {
+			continue
+		}
+		custom := passwork.PasswordCustomData{Name: s.Key, Value: passwork.NewSecret(s.Value.Content), Type: "text"}
+		if s.Value.Protected {
+			custom.Type = "password"
+		}
+		item.Custom = append(item.Custom, custom)
+	}
+
+	for i, ref := range entry.Binaries {
+		if ref.Value.Ref < 0 || ref.Value.Ref >= len(db.Binaries) {
+			continue
+		}
+		file := fmt.Sprintf("attachments/%s/%d", id, i)
+		files[file] = db.Binaries[ref.Value.Ref]
+		item.Attachments = append(item.Attachments, passwork.ArchiveAttachment{Id: fmt.Sprint(i), Name: ref.Key, File: file})
+	}
+
+	return item
+}
+
+// FromManifest builds a database from a vault snapshot. Folders become groups
+// below a root group named after the vault.
+func FromManifest(manifest passwork.ArchiveManifest, files map[string][]byte) *Database {
+	db := &Database{
+		Meta: Meta{Generator: "passwork-client-go", DatabaseName: manifest.Vault.Name},
+		Root: Root{Group: Group{UUID: NewUUID(), Name: manifest.Vault.Name, Times: NewTimes()}},
+	}
+	if db.Root.Group.Name == "" {
+		db.Root.Group.Name = "Root"
+	}
+
+	// Groups are built bottom up, so collect children per parent first.
+	// Parents outside the manifest are the vault root, as on import.
+	known := make(map[string]bool, len(manifest.Folders))
+	for _, folder := range manifest.Folders {
+		known[folder.Id] = true
+	}
+	children := make(map[string][]passwork.ArchiveFolder)
+	for _, folder := range manifest.Folders {
+		parentId := folder.ParentId
+		if !known[parentId] || parentId == folder.Id {
+			parentId = ""
+		}
+		children[parentId] = append(children[parentId], folder)
+	}
+	entries := make(map[string][]Entry)
+	for _, item := range manifest.Items {
+		folderId := item.FolderId
+		if !known[folderId] {
+			folderId = ""
+		}
+		entries[folderId] = append(entries[folderId], db.entry(item, files))
+	}
+
+	var build func(folderId string) ([]Group, []Entry)
+	build = func(folderId string) ([]Group, []Entry) {
+		var groups []Group
+		for _, folder := range children[folderId] {
+			group := Group{UUID: NewUUID(), Name: folder.Name, Times: NewTimes()}
+			group.Groups, group.Entries = build(folder.Id)
+			groups = append(groups, group)
+		}
+		return groups, entries[folderId]
+	}
+	db.Root.Group.Groups, db.Root.Group.Entries = build("")
+
+	return db
+}
+
+func (db *Database) entry(item passwork.ArchiveItem, files map[string][]byte) Entry {
+	entry := Entry{UUID: NewUUID(), Times: NewTimes(), Tags: strings.Join(item.Tags, ";")}
+	entry.Set(KeyTitle, item.Name, false)
+	entry.Set(KeyUserName, item.Login, false)
+	entry.Set(KeyPassword, item.Password.Reveal(), true)
+	entry.Set(KeyURL, item.Url, false)
+	entry.Set(KeyNotes, item.Description, false)
+
+	for _, custom := range item.Custom {
+		if custom.Name == "" || standardKeys[custom.Name] {
+			continue
+		}
+		entry.Set(custom.Name, custom.Value.Reveal(), custom.Type == "password")
+	}
+
+	for _, attachment := range item.Attachments {
+		data, ok := files[attachment.File]
+		if !ok {
+			continue
+		}
+		ref := BinaryRef{Key: attachment.Name}
+		ref.Value.Ref = len(db.Binaries)
+		db.Binaries = append(db.Binaries, data)
+		entry.Binaries = append(entry.Binaries, ref)
+	}
+
+	return entry
+}
+
+// Import mirrors the groups and entries of a database into a Passwork vault
+func Import(ctx context.Context, client *passwork.Client, db *Database, vaultId string, opts passwork.ImportOptions) (passwork.ImportReport, error) {
+	manifest, files := ToManifest(db)
+	return client.ImportManifest(ctx, manifest, files, vaultId, opts)
+}
+
+// Export writes a Passwork vault as KDBX 4 database for offline access
+func Export(ctx context.Context, client *passwork.Client, vaultId string, w io.Writer, credentials Credentials, opts WriteOptions) error {
+	manifest, files, err := client.SnapshotVaultWithAttachments(ctx, vaultId)
+	if err != nil {
+		return err
+	}
+	return FromManifest(manifest, files).Write(w, credentials, opts)
+}
+
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,221 @@
+// Writes external.kdbx, a KDBX 4.0 file laid out like KeePassXC writes it,
+// from the format specification and Node's crypto rather than this package:
+//
+//	node external.js
+//
+// Password "passwork", AES-KDF, ChaCha20 payload cipher, gzip compression,
+// ChaCha20 inner stream and one protected attachment in the inner header.
+"use strict";
+
+const crypto = require("crypto");
+const fs = require("fs");
+const path = require("path");
+const zlib = require("zlib");
+
+const password = "passwork";
+const rounds = 6000n;
+
+const u32 = (n) => { const b = Buffer.alloc(4); b.writeUInt32LE(n); return b; };
+const u64 = (n) => { const b = Buffer.alloc(8); b.writeBigUInt64LE(BigInt(n)); return b; };
+const sha256 = (...parts) => crypto.createHash("sha256").update(Buffer.concat(parts)).digest();
+const sha512 = (...parts) => crypto.createHash("sha512").update(Buffer.concat(parts)).digest();
+const field = (id, value) => Buffer.concat([Buffer.from([id]), u32(value.length), value]);
+
+// chacha20 takes the 32 bit block counter followed by the 96 bit nonce
+const chacha20 = (key, nonce) => crypto.createCipheriv("chacha20", key, Buffer.concat([u32(0), nonce]));
+
+function variantDictionary(entries) {
+	const parts = [Buffer.from([0x00, 0x01])];
+	for (const [type, key, value] of entries) {
+		const name = Buffer.from(key);
+		parts.push(Buffer.from([type]), u32(name.length), name, u32(value.length), value);
+	}
+	parts.push(Buffer.from([0x00]));
+	return Buffer.concat(parts);
+}
+
+// Fixed values keep the file stable when it is regenerated
+const seed = (label, size) => sha512(Buffer.from("passwork-client-go " + label)).subarray(0, size);
+const masterSeed = seed("master seed", 32);
+const kdfSeed = seed("kdf seed", 32);
+const iv = seed("iv", 12);
+const streamKey = sha512(Buffer.from("passwork-client-go stream key"));
+const uuid = (label) => seed(label, 16).toString("base64");
+const time = (date) => u64(BigInt(Math.floor(Date.parse(date) / 1000)) + 62135596800n).toString("base64");
+
+const kdfAES = Buffer.from("c9d9f39a628a4460bf740d08c18a4fea", "hex");
+const cipherChaCha20 = Buffer.from("d6038a2b8b6f4cb5a524339a31dbb59a", "hex");
+
+const header = Buffer.concat([
+	u32(0x9aa2d903), u32(0xb54bfb67), u32(0x00040000),
+	field(2, cipherChaCha20),
+	field(3, u32(1)),
+	field(4, masterSeed),
+	field(7, iv),
+	field(11, variantDictionary([
+		[0x42, "$UUID", kdfAES],
+		[0x05, "R", u64(rounds)],
+		[0x42, "S", kdfSeed],
+	])),
+	field(0, Buffer.from("\r\n\r\n")),
+]);
+
+// AES-KDF: encrypt the composite key with the seed in ECB mode rounds times
+let transformed = sha256(sha256(Buffer.from(password)));
+const aes = crypto.createCipheriv("aes-256-ecb", kdfSeed, null).setAutoPadding(false);
+for (let i = 0n; i < rounds; i++) {
+	transformed = aes.update(transformed);
+}
+transformed = sha256(transformed);
+
+const encryptionKey = sha256(masterSeed, transformed);
+const hmacKey = sha512(masterSeed, transformed, Buffer.from([0x01]));
+const blockKey = (index) => sha512(index, hmacKey);
+
+const times = (created) => `<Times>
+				<LastModificationTime>${time(created)}</LastModificationTime>
+				<CreationTime>${time(created)}</CreationTime>
+				<LastAccessTime>${time(created)}</LastAccessTime>
+				<ExpiryTime>${time(created)}</ExpiryTime>
+				<Expires>False</Expires>
+				<UsageCount>0</UsageCount>
+				<LocationChanged>${time(created)}</LocationChanged>
+			</Times>`;
+
+// Protected values are XORed with the inner stream in document order
+const innerKey = sha512(streamKey);
+const inner = chacha20(innerKey.subarray(0, 32), innerKey.subarray(32, 44));
+const protect = (value) => inner.update(Buffer.from(value)).toString("base64");
+
+const xml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<Generator>external.js</Generator>
+		<DatabaseName>Passwork</DatabaseName>
+		<DatabaseNameChanged>${time("2024-05-01T10:00:00Z")}</DatabaseNameChanged>
+		<DatabaseDescription/>
+		<DefaultUserName/>
+		<MaintenanceHistoryDays>365</MaintenanceHistoryDays>
+		<Color/>
+		<MasterKeyChanged>${time("2024-05-01T10:00:00Z")}</MasterKeyChanged>
+		<MasterKeyChangeRec>-1</MasterKeyChangeRec>
+		<MasterKeyChangeForce>-1</MasterKeyChangeForce>
+		<MemoryProtection>
+			<ProtectTitle>False</ProtectTitle>
+			<ProtectUserName>False</ProtectUserName>
+			<ProtectPassword>True</ProtectPassword>
+			<ProtectURL>False</ProtectURL>
+			<ProtectNotes>False</ProtectNotes>
+		</MemoryProtection>
+		<CustomIcons/>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>${uuid("recycle bin")}</RecycleBinUUID>
+		<RecycleBinChanged>${time("2024-05-01T10:00:00Z")}</RecycleBinChanged>
+		<EntryTemplatesGroup>AAAAAAAAAAAAAAAAAAAAAA==</EntryTemplatesGroup>
+		<EntryTemplatesGroupChanged>${time("2024-05-01T10:00:00Z")}</EntryTemplatesGroupChanged>
+		<LastSelectedGroup>AAAAAAAAAAAAAAAAAAAAAA==</LastSelectedGroup>
+		<LastTopVisibleGroup>AAAAAAAAAAAAAAAAAAAAAA==</LastTopVisibleGroup>
+		<HistoryMaxItems>10</HistoryMaxItems>
+		<HistoryMaxSize>6291456</HistoryMaxSize>
+		<SettingsChanged>${time("2024-05-01T10:00:00Z")}</SettingsChanged>
+		<CustomData>
+			<Item>
+				<Key>KPXC_DECRYPTION_TIME_PREFERENCE</Key>
+				<Value>1000</Value>
+			</Item>
+		</CustomData>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>${uuid("root")}</UUID>
+			<Name>Root</Name>
+			<Notes/>
+			<IconID>48</IconID>
+			${times("2024-05-01T10:00:00Z")}
+			<IsExpanded>True</IsExpanded>
+			<DefaultAutoTypeSequence/>
+			<EnableAutoType>null</EnableAutoType>
+			<EnableSearching>null</EnableSearching>
+			<LastTopVisibleEntry>AAAAAAAAAAAAAAAAAAAAAA==</LastTopVisibleEntry>
+			<Group>
+				<UUID>${uuid("servers")}</UUID>
+				<Name>Servers</Name>
+				<Notes/>
+				<IconID>48</IconID>
+				${times("2024-05-01T10:01:00Z")}
+				<IsExpanded>True</IsExpanded>
+				<DefaultAutoTypeSequence/>
+				<EnableAutoType>null</EnableAutoType>
+				<EnableSearching>null</EnableSearching>
+				<LastTopVisibleEntry>AAAAAAAAAAAAAAAAAAAAAA==</LastTopVisibleEntry>
+				<Entry>
+					<UUID>${uuid("postgres")}</UUID>
+					<IconID>0</IconID>
+					<ForegroundColor/>
+					<BackgroundColor/>
+					<OverrideURL/>
+					<Tags>db;prod</Tags>
+					${times("2024-05-01T10:02:00Z")}
+					<String>
+						<Key>Notes</Key>
+						<Value>primary</Value>
+					</String>
+					<String>
+						<Key>Password</Key>
+						<Value Protected="True">${protect("s3cret")}</Value>
+					</String>
+					<String>
+						<Key>Title</Key>
+						<Value>postgres</Value>
+					</String>
+					<String>
+						<Key>URL</Key>
+						<Value>https://db.example.com</Value>
+					</String>
+					<String>
+						<Key>UserName</Key>
+						<Value>admin</Value>
+					</String>
+					<String>
+						<Key>port</Key>
+						<Value>5432</Value>
+					</String>
+					<Binary>
+						<Key>ca.pem</Key>
+						<Value Ref="0"/>
+					</Binary>
+					<AutoType>
+						<Enabled>True</Enabled>
+						<DataTransferObfuscation>0</DataTransferObfuscation>
+						<DefaultSequence/>
+					</AutoType>
+					<History/>
+				</Entry>
+			</Group>
+		</Group>
+		<DeletedObjects/>
+	</Root>
+</KeePassFile>
+`;
+
+const innerHeader = Buffer.concat([
+	field(1, u32(3)),
+	field(2, streamKey),
+	field(3, Buffer.concat([Buffer.from([0x01]), Buffer.from("-----BEGIN CERTIFICATE-----")])),
+	field(0, Buffer.alloc(0)),
+]);
+const compressed = zlib.gzipSync(Buffer.concat([innerHeader, Buffer.from(xml)]));
+const payload = chacha20(encryptionKey, iv).update(compressed);
+
+const hmac = (index, ...parts) => crypto.createHmac("sha256", blockKey(index)).update(Buffer.concat(parts)).digest();
+const blocks = [];
+for (const [index, block] of [[0n, payload], [1n, Buffer.alloc(0)]]) {
+	blocks.push(hmac(u64(index), u64(index), u32(block.length), block), u32(block.length), block);
+}
+
+fs.writeFileSync(path.join(__dirname, "external.kdbx"), Buffer.concat([
+	header,
+	sha256(header),
+	hmac(u64(0xffffffffffffffffn), header),
+	...blocks,
+]));
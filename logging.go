@@ -0,0 +1,110 @@
+package passwork
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Body fields holding secrets, matched case-insensitively
+var secretFields = map[string]bool{
+	"cryptedpassword": true,
+	"cryptedkey":      true,
+	"password":        true,
+	"passwordcrypted": true,
+	"passwordhash":    true,
+	"mpcrypted":       true,
+	"masterhash":      true,
+	"salt":            true,
+	"value":           true, // Custom field values
+	"token":           true,
+	"refreshtoken":    true,
+	"apikey":          true,
+	"encrypteddata":   true,
+}
+
+// WithLogger emits debug events for requests and responses to logger.
+// Authorization headers and secret body fields are redacted. Without this
+// option the client logs nothing.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+func (c *Client) logRequest(ctx context.Context, req *http.Request, body []byte) {
+	logger := c.log()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Any("headers", redactHeaders(req.Header)),
+	}
+	if req.URL.RawQuery != "" {
+		attrs = append(attrs, slog.String("query", req.URL.RawQuery))
+	}
+	if len(body) > 0 {
+		attrs = append(attrs, slog.Any("body", redactBody(body)))
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "passwork request", attrs...)
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	redactedHeader := make(map[string]string, len(header))
+	for name, values := range header {
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "Cookie") {
+			redactedHeader[name] = redacted
+			continue
+		}
+		redactedHeader[name] = strings.Join(values, ", ")
+	}
+	return redactedHeader
+}
+
+// redactBody replaces secret fields of a JSON body, other bodies are only described by their size
+func redactBody(body []byte) any {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return map[string]int{"size": len(body)}
+	}
+	return redactValue(value)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if secretFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = redactValue(element)
+		}
+	}
+	return value
+}
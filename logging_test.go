@@ -0,0 +1,63 @@
+package passwork
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"id":"pw1"}}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(server.URL, "api-key-value", time.Second, WithLogger(logger))
+
+	_, err := client.AddPassword(PasswordRequest{
+		Name:            "db",
+		VaultId:         "v1",
+		CryptedPassword: NewSecret("c2VjcmV0LXZhbHVl"),
+		Custom:          []PasswordCustomData{{Name: "token", Value: NewSecret("custom-secret"), Type: "password"}},
+	})
+	require.NoError(t, err)
+	_, err = client.GetPassword("pw1")
+	require.NoError(t, err)
+
+	logged := output.String()
+	lines := strings.Split(strings.TrimSpace(logged), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], `"msg":"passwork request"`)
+	assert.Contains(t, lines[0], `"method":"POST"`)
+	assert.Contains(t, lines[0], `"name":"db"`)
+	assert.Contains(t, lines[1], `"msg":"passwork response"`)
+	assert.Contains(t, lines[1], `"status":200`)
+	assert.Contains(t, lines[1], `"duration"`)
+	assert.Contains(t, lines[3], `"path":"/items/pw1"`)
+
+	assert.Contains(t, logged, redacted)
+	assert.NotContains(t, logged, "api-key-value")
+	assert.NotContains(t, logged, "c2VjcmV0LXZhbHVl")
+	assert.NotContains(t, logged, "custom-secret")
+}
+
+func TestLoggerSilentByDefault(t *testing.T) {
+	var output bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&output)
+
+	client := NewClient("http://127.0.0.1:1", "key", time.Second)
+	_, err := client.GetPassword("pw1")
+	assert.Error(t, err)
+	assert.Empty(t, output.String())
+}
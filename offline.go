@@ -0,0 +1,371 @@
+package passwork
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treasure33/passwork-client-go/internal/archive"
+)
+
+const (
+	offlineMagic = "PWKSNAP1"
+
+	// offlineSaveInterval batches snapshot writes, changes within the interval
+	// after a write are saved together once it has passed
+	offlineSaveInterval = 10 * time.Second
+)
+
+// ErrOffline is returned while the server can't be reached in offline mode,
+// for writes and for reads of items and folders missing from the snapshot
+var ErrOffline = errors.New("passwork: server unreachable in offline mode")
+
+// OfflineSnapshot is an encrypted on-disk copy of the items and folders read
+// through a client. It is refreshed by successful reads and deletes and serves
+// GetPassword, SearchPassword and GetFolder while the server is unreachable.
+// Changes are written to disk at most every ten seconds, call Flush before
+// exiting to write pending changes.
+type OfflineSnapshot struct {
+	mu       sync.Mutex
+	path     string
+	vaultIds []string
+	salt     []byte
+	aead     cipher.AEAD
+	data     offlineData
+
+	saveInterval time.Duration
+	savedAt      time.Time
+	timer        *time.Timer // Pending write of changes since savedAt
+	saveErr      error       // Error of the last delayed write
+}
+
+type offlineData struct {
+	SavedAt string
+	Items   map[string]PasswordResponseData
+	Full    map[string]bool // Items fetched with GetPassword, search results lack secrets
+	Folders map[string]FolderResponseData
+}
+
+// NewOfflineSnapshot opens or creates the snapshot file at path. Only items and
+// folders of the given vaults are kept, all vaults if none are given.
+func NewOfflineSnapshot(path, passphrase string, vaultIds ...string) (*OfflineSnapshot, error) {
+	return newOfflineSnapshot(path, passphrase, archive.DefaultIterations, vaultIds)
+}
+
+func newOfflineSnapshot(path, passphrase string, iterations int, vaultIds []string) (*OfflineSnapshot, error) {
+	if passphrase == "" {
+		return nil, errors.New("offline: passphrase must not be empty")
+	}
+
+	snapshot := &OfflineSnapshot{
+		path:         path,
+		vaultIds:     vaultIds,
+		saveInterval: offlineSaveInterval,
+		data: offlineData{
+			Items:   make(map[string]PasswordResponseData),
+			Full:    make(map[string]bool),
+			Folders: make(map[string]FolderResponseData),
+		},
+	}
+
+	file, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		snapshot.salt = make([]byte, 16)
+		rand.Read(snapshot.salt)
+	case err != nil:
+		return nil, err
+	case len(file) < len(offlineMagic)+16 || string(file[:len(offlineMagic)]) != offlineMagic:
+		return nil, fmt.Errorf("offline: %s is not a snapshot file", path)
+	default:
+		snapshot.salt = file[len(offlineMagic) : len(offlineMagic)+16]
+	}
+
+	block, _ := aes.NewCipher(archive.DeriveKey(passphrase, snapshot.salt, iterations))
+	snapshot.aead, _ = cipher.NewGCM(block)
+
+	if file != nil {
+		sealed := file[len(offlineMagic)+16:]
+		nonceSize := snapshot.aead.NonceSize()
+		if len(sealed) < nonceSize {
+			return nil, errors.New("offline: snapshot file is corrupted")
+		}
+		plaintext, err := snapshot.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(offlineMagic))
+		if err != nil {
+			return nil, errors.New("offline: wrong passphrase or corrupted snapshot file")
+		}
+		if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&snapshot.data); err != nil {
+			return nil, fmt.Errorf("offline: decode snapshot: %w", err)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// WithOfflineSnapshot serves reads from the snapshot when the server is unreachable
+func WithOfflineSnapshot(snapshot *OfflineSnapshot) Option {
+	return func(c *Client) {
+		c.offline = snapshot
+	}
+}
+
+// SavedAt returns when the snapshot was last written
+func (s *OfflineSnapshot) SavedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	savedAt, _ := time.Parse(time.RFC3339, s.data.SavedAt)
+	return savedAt
+}
+
+func (s *OfflineSnapshot) selected(vaultId string) bool {
+	return len(s.vaultIds) == 0 || slices.Contains(s.vaultIds, vaultId)
+}
+
+func (s *OfflineSnapshot) storePassword(data PasswordResponseData) error {
+	if !s.selected(data.VaultId) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Items[data.Id] = data
+	s.data.Full[data.Id] = true
+	return s.changed()
+}
+
+// storeSearch replaces the stored items matching request with the results
+func (s *OfflineSnapshot) storeSearch(request PasswordSearchRequest, items []PasswordResponseData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := make(map[string]bool, len(items))
+	for _, item := range items {
+		found[item.Id] = true
+		if s.selected(item.VaultId) && !s.data.Full[item.Id] {
+			s.data.Items[item.Id] = item
+		}
+	}
+	for id, item := range s.data.Items {
+		if !found[id] && matchesSearch(request, item) {
+			delete(s.data.Items, id)
+			delete(s.data.Full, id)
+		}
+	}
+	return s.changed()
+}
+
+func (s *OfflineSnapshot) storeFolder(data FolderResponseData) error {
+	if !s.selected(data.VaultId) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Folders[data.Id] = data
+	return s.changed()
+}
+
+func (s *OfflineSnapshot) removePassword(pwId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.Items, pwId)
+	delete(s.data.Full, pwId)
+	return s.changed()
+}
+
+// removeFolder removes a folder with its subfolders and their items
+func (s *OfflineSnapshot) removeFolder(folderId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := map[string]bool{folderId: true}
+	for found := true; found; {
+		found = false
+		for id, folder := range s.data.Folders {
+			if removed[folder.ParentId] && !removed[id] {
+				removed[id], found = true, true
+			}
+		}
+	}
+	for id := range removed {
+		delete(s.data.Folders, id)
+	}
+	for id, item := range s.data.Items {
+		if removed[item.FolderId] {
+			delete(s.data.Items, id)
+			delete(s.data.Full, id)
+		}
+	}
+	return s.changed()
+}
+
+func (s *OfflineSnapshot) password(pwId string) (PasswordResponseData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.data.Items[pwId]
+	return data, ok && s.data.Full[pwId]
+}
+
+func (s *OfflineSnapshot) folder(folderId string) (FolderResponseData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.data.Folders[folderId]
+	return data, ok
+}
+
+// search filters the stored items like the server would
+func (s *OfflineSnapshot) search(request PasswordSearchRequest) []PasswordResponseData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []PasswordResponseData
+	for _, item := range s.data.Items {
+		if matchesSearch(request, item) {
+			result = append(result, item)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b PasswordResponseData) int { return strings.Compare(a.Name, b.Name) })
+	return result
+}
+
+func matchesSearch(request PasswordSearchRequest, item PasswordResponseData) bool {
+	if request.VaultId != "" && item.VaultId != request.VaultId {
+		return false
+	}
+	if request.Query != "" && !strings.Contains(strings.ToLower(item.Name), strings.ToLower(request.Query)) {
+		return false
+	}
+	if len(request.Colors) > 0 && !slices.Contains(request.Colors, item.Color) {
+		return false
+	}
+	if len(request.Tags) > 0 && !slices.ContainsFunc(request.Tags, func(tag string) bool { return slices.Contains(item.Tags, tag) }) {
+		return false
+	}
+	return true
+}
+
+// Flush writes pending changes to disk
+func (s *OfflineSnapshot) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer == nil {
+		return nil
+	}
+	s.timer.Stop()
+	s.timer = nil
+	return s.save()
+}
+
+// changed saves the snapshot, or schedules the write if the last one was
+// less than saveInterval ago. It returns the error of an earlier delayed
+// write, the caller holds the lock.
+func (s *OfflineSnapshot) changed() error {
+	err := s.saveErr
+	s.saveErr = nil
+
+	if s.timer != nil {
+		return err
+	}
+	if wait := s.saveInterval - time.Since(s.savedAt); wait > 0 {
+		s.timer = time.AfterFunc(wait, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.timer != nil {
+				s.timer = nil
+				s.saveErr = s.save()
+			}
+		})
+		return err
+	}
+	return errors.Join(err, s.save())
+}
+
+// save writes the snapshot atomically, the caller holds the lock
+func (s *OfflineSnapshot) save() error {
+	s.savedAt = time.Now()
+	s.data.SavedAt = time.Now().UTC().Format(time.RFC3339)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s.data); err != nil {
+		return err
+	}
+	plaintext := buf.Bytes()
+
+	nonce := make([]byte, s.aead.NonceSize())
+	rand.Read(nonce)
+
+	var file bytes.Buffer
+	file.WriteString(offlineMagic)
+	file.Write(s.salt)
+	file.Write(nonce)
+	file.Write(s.aead.Seal(nil, nonce, plaintext, []byte(offlineMagic)))
+	clear(plaintext)
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".passwork-snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(file.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// isUnreachable reports whether a request failed because the server can't be
+// reached or is down for maintenance
+func isUnreachable(statusCode int, err error) bool {
+	if err != nil {
+		// A server failing certificate checks is reachable but mustn't be trusted
+		var pinErr *PinMismatchError
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &pinErr) || errors.As(err, &certErr) {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && !errors.Is(err, context.Canceled)
+	}
+	return statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout
+}
+
+// offlineStore refreshes the offline snapshot with a read if one is configured
+// and the client doesn't bypass it
+func (c *Client) offlineStore(store func(*OfflineSnapshot) error) {
+	if !c.cacheBypass {
+		c.offlineUpdate(store)
+	}
+}
+
+// offlineUpdate changes the offline snapshot if one is configured. A failed
+// write doesn't fail the request, the previous snapshot stays in place.
+func (c *Client) offlineUpdate(update func(*OfflineSnapshot) error) {
+	if c.offline == nil {
+		return
+	}
+	if err := update(c.offline); err != nil {
+		c.log().Warn("passwork offline snapshot update failed", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,217 @@
+package passwork
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOfflineTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/items/search":
+			w.Write([]byte(`{"status":"success","data":[` +
+				`{"id":"pw1","vaultId":"v1","name":"database","tags":["prod"]},` +
+				`{"id":"pw2","vaultId":"v1","name":"mail","color":3},` +
+				`{"id":"pw3","vaultId":"v2","name":"other vault"}]}`))
+		case r.URL.Path == "/items/pw1":
+			w.Write([]byte(`{"status":"success","data":{"id":"pw1","vaultId":"v1","name":"database","cryptedPassword":"c2VjcmV0LXZhbHVl","tags":["prod"]}}`))
+		case r.URL.Path == "/folders/f1":
+			w.Write([]byte(`{"status":"success","data":{"id":"f1","vaultId":"v1","name":"infra"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestOfflineSnapshot(t *testing.T, path string, vaultIds ...string) *OfflineSnapshot {
+	snapshot, err := newOfflineSnapshot(path, "snapshot-passphrase", 1000, vaultIds)
+	require.NoError(t, err)
+	t.Cleanup(func() { snapshot.Flush() })
+	return snapshot
+}
+
+func TestOfflineFallback(t *testing.T) {
+	server := newOfflineTestServer(t)
+	path := filepath.Join(t.TempDir(), "snapshot")
+	client := NewClient(server.URL, "key", time.Second, WithOfflineSnapshot(newTestOfflineSnapshot(t, path, "v1")))
+
+	online, err := client.GetPassword("pw1")
+	require.NoError(t, err)
+	assert.False(t, online.Stale)
+	_, err = client.SearchPassword(PasswordSearchRequest{})
+	require.NoError(t, err)
+	_, err = client.GetFolder("f1")
+	require.NoError(t, err)
+
+	server.Close()
+
+	t.Run("GetPassword", func(t *testing.T) {
+		response, err := client.GetPassword("pw1")
+		require.NoError(t, err)
+		assert.True(t, response.Stale)
+		assert.Equal(t, "c2VjcmV0LXZhbHVl", response.Data.CryptedPassword.Reveal())
+
+		// Search results carry no secrets and aren't served as full items
+		_, err = client.GetPassword("pw2")
+		assert.ErrorIs(t, err, ErrOffline)
+		assert.NotContains(t, err.Error(), "writes", "Read misses shouldn't be reported as failed writes.")
+	})
+
+	t.Run("SearchPassword", func(t *testing.T) {
+		response, err := client.SearchPassword(PasswordSearchRequest{})
+		require.NoError(t, err)
+		assert.True(t, response.Stale)
+		assert.Len(t, response.Data, 2, "items of unselected vaults are not stored")
+
+		response, err = client.SearchPassword(PasswordSearchRequest{Query: "DATA"})
+		require.NoError(t, err)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "pw1", response.Data[0].Id)
+
+		response, err = client.SearchPassword(PasswordSearchRequest{Colors: []int{3}})
+		require.NoError(t, err)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "pw2", response.Data[0].Id)
+
+		response, err = client.SearchPassword(PasswordSearchRequest{Tags: []string{"prod"}})
+		require.NoError(t, err)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "pw1", response.Data[0].Id)
+	})
+
+	t.Run("GetFolder", func(t *testing.T) {
+		response, err := client.GetFolder("f1")
+		require.NoError(t, err)
+		assert.True(t, response.Stale)
+		assert.Equal(t, "infra", response.Data.Name)
+	})
+
+	t.Run("Writes", func(t *testing.T) {
+		_, err := client.AddPassword(PasswordRequest{Name: "new", VaultId: "v1"})
+		assert.True(t, errors.Is(err, ErrOffline))
+	})
+
+	t.Run("Encrypted", func(t *testing.T) {
+		file, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.False(t, strings.Contains(string(file), "database"))
+		assert.False(t, strings.Contains(string(file), "c2VjcmV0LXZhbHVl"))
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		reloaded := NewClient(server.URL, "key", time.Second, WithOfflineSnapshot(newTestOfflineSnapshot(t, path)))
+		response, err := reloaded.GetPassword("pw1")
+		require.NoError(t, err)
+		assert.True(t, response.Stale)
+
+		_, err = newOfflineSnapshot(path, "wrong-passphrase", 1000, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestOfflineMaintenance(t *testing.T) {
+	var maintenance atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maintenance.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"id":"pw1","vaultId":"v1","name":"database"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", time.Second, WithOfflineSnapshot(newTestOfflineSnapshot(t, filepath.Join(t.TempDir(), "snapshot"))))
+	_, err := client.GetPassword("pw1")
+	require.NoError(t, err)
+
+	maintenance.Store(true)
+	response, err := client.GetPassword("pw1")
+	require.NoError(t, err)
+	assert.True(t, response.Stale)
+
+	_, err = client.DeletePassword("pw1")
+	assert.ErrorIs(t, err, ErrOffline)
+}
+
+func TestOfflineEviction(t *testing.T) {
+	var deleted atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			w.Write([]byte(`{"status":"success","data":"deleted"}`))
+		case r.URL.Path == "/items/search" && deleted.Load():
+			w.Write([]byte(`{"status":"success","data":[{"id":"pw1","vaultId":"v1","name":"database"}]}`))
+		case r.URL.Path == "/items/search":
+			w.Write([]byte(`{"status":"success","data":[` +
+				`{"id":"pw1","vaultId":"v1","name":"database"},` +
+				`{"id":"pw2","vaultId":"v1","name":"mail"},` +
+				`{"id":"pw3","vaultId":"v1","name":"nested","folderId":"f2"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/items/"):
+			id := strings.TrimPrefix(r.URL.Path, "/items/")
+			w.Write([]byte(`{"status":"success","data":{"id":"` + id + `","vaultId":"v1","name":"database","folderId":"f2"}}`))
+		case r.URL.Path == "/folders/f1":
+			w.Write([]byte(`{"status":"success","data":{"id":"f1","vaultId":"v1","name":"infra"}}`))
+		case r.URL.Path == "/folders/f2":
+			w.Write([]byte(`{"status":"success","data":{"id":"f2","vaultId":"v1","name":"db","parentId":"f1"}}`))
+		}
+	}))
+	defer server.Close()
+
+	snapshot := newTestOfflineSnapshot(t, filepath.Join(t.TempDir(), "snapshot"))
+	client := NewClient(server.URL, "key", time.Second, WithOfflineSnapshot(snapshot))
+
+	_, err := client.SearchPassword(PasswordSearchRequest{VaultId: "v1"})
+	require.NoError(t, err)
+	for _, read := range []func() error{
+		func() error { _, err := client.GetPassword("pw4"); return err },
+		func() error { _, err := client.GetFolder("f1"); return err },
+		func() error { _, err := client.GetFolder("f2"); return err },
+	} {
+		require.NoError(t, read())
+	}
+
+	// The search no longer returns pw2, pw3 is deleted with its folder
+	deleted.Store(true)
+	_, err = client.SearchPassword(PasswordSearchRequest{VaultId: "v1", Query: "mail"})
+	require.NoError(t, err)
+	assert.NotContains(t, snapshot.data.Items, "pw2")
+
+	_, err = client.DeletePassword("pw1")
+	require.NoError(t, err)
+	assert.NotContains(t, snapshot.data.Items, "pw1")
+
+	_, err = client.DeleteFolder("f1")
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.data.Folders)
+	assert.Empty(t, snapshot.data.Items, "Items of deleted subfolders should be removed.")
+	assert.Empty(t, snapshot.data.Full)
+}
+
+func TestOfflineSaveBatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	snapshot := newTestOfflineSnapshot(t, path)
+	snapshot.saveInterval = time.Hour
+
+	require.NoError(t, snapshot.storeFolder(FolderResponseData{Id: "f1", Name: "first"}))
+	require.NoError(t, snapshot.storeFolder(FolderResponseData{Id: "f2", Name: "second"}))
+
+	reopened := newTestOfflineSnapshot(t, path)
+	assert.Contains(t, reopened.data.Folders, "f1", "The first change should be written immediately.")
+	assert.NotContains(t, reopened.data.Folders, "f2", "Changes within the interval should be delayed.")
+
+	require.NoError(t, snapshot.Flush())
+	reopened = newTestOfflineSnapshot(t, path)
+	assert.Contains(t, reopened.data.Folders, "f2", "Flush should write pending changes.")
+}
@@ -0,0 +1,30 @@
+module github.com/treasure33/passwork-client-go/otelpasswork
+
+go 1.23.5
+
+require (
+	github.com/stretchr/testify v1.10.0
+	github.com/treasure33/passwork-client-go v0.3.0
+	go.opentelemetry.io/otel v1.37.0
+	go.opentelemetry.io/otel/metric v1.37.0
+	go.opentelemetry.io/otel/sdk v1.37.0
+	go.opentelemetry.io/otel/sdk/metric v1.37.0
+	go.opentelemetry.io/otel/trace v1.37.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Develop against the client in this repository. Consumers ignore replace and
+// resolve the client release required above, this module is released with
+// otelpasswork/vX.Y.Z tags.
+replace github.com/treasure33/passwork-client-go => ../
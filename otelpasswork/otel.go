@@ -0,0 +1,174 @@
+// Package otelpasswork instruments a Passwork client with OpenTelemetry.
+//
+// Every logical operation such as GetPassword or AddFolder is recorded as a
+// span with a child span per HTTP attempt, trace context is propagated in
+// the request headers:
+//
+//	client := passwork.NewClient(host, apiKey, timeout, passwork.WithInstrumentation(otelpasswork.New()))
+package otelpasswork
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	passwork "github.com/treasure33/passwork-client-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ScopeName = "github.com/treasure33/passwork-client-go/otelpasswork"
+
+const (
+	OperationKey  = attribute.Key("passwork.operation")
+	StatusKey     = attribute.Key("passwork.status")
+	APICodeKey    = attribute.Key("passwork.api_code")
+	statusOK      = "ok"
+	statusError   = "error"
+	errorTypeKey  = attribute.Key("error.type")
+	methodKey     = attribute.Key("http.request.method")
+	statusCodeKey = attribute.Key("http.response.status_code")
+	serverKey     = attribute.Key("server.address")
+	pathKey       = attribute.Key("url.path")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option configures the instrumentation
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider, defaults to the global one
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider, defaults to the global one
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithPropagators sets the propagators injecting trace context into requests, defaults to the global ones
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+type instrumentation struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+
+	operations metric.Int64Counter
+	errors     metric.Int64Counter
+	duration   metric.Float64Histogram
+}
+
+// New returns instrumentation recording spans and metrics, pass it to passwork.WithInstrumentation.
+//
+// The metrics are passwork.client.operations and passwork.client.operation.errors
+// counting operations and failed operations, and passwork.client.operation.duration
+// measuring their latency in seconds, all with the operation name as attribute.
+func New(opts ...Option) passwork.Instrumentation {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	i := &instrumentation{
+		tracer:      cfg.tracerProvider.Tracer(ScopeName),
+		propagators: cfg.propagators,
+	}
+
+	// Instrument creation only fails for invalid names, errors go to the global handler
+	var err error
+	if i.operations, err = meter.Int64Counter("passwork.client.operations",
+		metric.WithDescription("Number of Passwork client operations"), metric.WithUnit("{operation}")); err != nil {
+		otel.Handle(err)
+	}
+	if i.errors, err = meter.Int64Counter("passwork.client.operation.errors",
+		metric.WithDescription("Number of failed Passwork client operations"), metric.WithUnit("{operation}")); err != nil {
+		otel.Handle(err)
+	}
+	if i.duration, err = meter.Float64Histogram("passwork.client.operation.duration",
+		metric.WithDescription("Duration of Passwork client operations"), metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+
+	return i
+}
+
+func (i *instrumentation) StartOperation(ctx context.Context, operation string) (context.Context, func(string, error)) {
+	start := time.Now()
+	ctx, span := i.tracer.Start(ctx, "passwork."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(OperationKey.String(operation)))
+
+	return ctx, func(code string, err error) {
+		attrs := []attribute.KeyValue{OperationKey.String(operation)}
+		if err != nil {
+			attrs = append(attrs, StatusKey.String(statusError))
+		} else {
+			attrs = append(attrs, StatusKey.String(statusOK))
+		}
+		set := metric.WithAttributes(attrs...)
+
+		i.operations.Add(ctx, 1, set)
+		i.duration.Record(ctx, time.Since(start).Seconds(), set)
+
+		if code != "" {
+			span.SetAttributes(APICodeKey.String(code))
+		}
+		span.SetAttributes(attrs[1])
+		if err != nil {
+			i.errors.Add(ctx, 1, metric.WithAttributes(OperationKey.String(operation), APICodeKey.String(code)))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (i *instrumentation) StartAttempt(req *http.Request) func(int, error) {
+	ctx, span := i.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(methodKey.String(req.Method), serverKey.String(req.URL.Hostname()), pathKey.String(req.URL.Path)))
+	i.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return func(status int, err error) {
+		if status > 0 {
+			span.SetAttributes(statusCodeKey.Int(status))
+		}
+		switch {
+		case err != nil:
+			span.SetAttributes(errorTypeKey.String("transport"))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case status >= 400:
+			span.SetAttributes(errorTypeKey.String(strconv.Itoa(status)))
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
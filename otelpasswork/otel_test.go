@@ -0,0 +1,121 @@
+package otelpasswork
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	passwork "github.com/treasure33/passwork-client-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstrumentation(t *testing.T) {
+	var traceparents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		switch r.URL.Path {
+		case "/folders":
+			w.Write([]byte(`{"status":"success","code":"folderCreated","data":{"id":"f1"}}`))
+		case "/items/pw1":
+			w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	client := passwork.NewClient(server.URL, "key", time.Second, passwork.WithInstrumentation(New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithPropagators(propagation.TraceContext{}),
+	)))
+
+	_, err := client.AddFolder(passwork.FolderRequest{Name: "f", VaultId: "v1"})
+	require.NoError(t, err)
+	_, err = client.GetPassword("pw1")
+	require.EqualError(t, err, "accessDenied")
+
+	ended := spans.Ended()
+	require.Len(t, ended, 4)
+
+	addAttempt, addFolder := ended[0], ended[1]
+	assert.Equal(t, "passwork.AddFolder", addFolder.Name())
+	assert.Equal(t, trace.SpanKindInternal, addFolder.SpanKind())
+	assert.Contains(t, addFolder.Attributes(), OperationKey.String("AddFolder"))
+	assert.Contains(t, addFolder.Attributes(), StatusKey.String("ok"))
+	assert.Contains(t, addFolder.Attributes(), APICodeKey.String("folderCreated"))
+	assert.Equal(t, codes.Unset, addFolder.Status().Code)
+
+	assert.Equal(t, "POST", addAttempt.Name())
+	assert.Equal(t, trace.SpanKindClient, addAttempt.SpanKind())
+	assert.Equal(t, addFolder.SpanContext().SpanID(), addAttempt.Parent().SpanID())
+	assert.Contains(t, addAttempt.Attributes(), attribute.Int("http.response.status_code", 200))
+	assert.Contains(t, addAttempt.Attributes(), attribute.String("url.path", "/folders"))
+
+	getPassword := ended[3]
+	assert.Equal(t, "passwork.GetPassword", getPassword.Name())
+	assert.Contains(t, getPassword.Attributes(), StatusKey.String("error"))
+	assert.Contains(t, getPassword.Attributes(), APICodeKey.String("accessDenied"))
+	assert.Equal(t, codes.Error, getPassword.Status().Code)
+
+	// The attempt span is propagated to the server
+	require.Len(t, traceparents, 2)
+	assert.Contains(t, traceparents[0], addAttempt.SpanContext().TraceID().String())
+	assert.Contains(t, traceparents[0], addAttempt.SpanContext().SpanID().String())
+
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &metrics))
+	require.Len(t, metrics.ScopeMetrics, 1)
+	byName := make(map[string]metricdata.Metrics)
+	for _, m := range metrics.ScopeMetrics[0].Metrics {
+		byName[m.Name] = m
+	}
+
+	operations := byName["passwork.client.operations"].Data.(metricdata.Sum[int64])
+	require.Len(t, operations.DataPoints, 2)
+	for _, point := range operations.DataPoints {
+		assert.Equal(t, int64(1), point.Value)
+	}
+
+	errors := byName["passwork.client.operation.errors"].Data.(metricdata.Sum[int64])
+	require.Len(t, errors.DataPoints, 1)
+	operation, _ := errors.DataPoints[0].Attributes.Value(OperationKey)
+	assert.Equal(t, "GetPassword", operation.AsString())
+
+	duration := byName["passwork.client.operation.duration"].Data.(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 2)
+}
+
+func TestAttemptFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	client := passwork.NewClient(server.URL, "key", time.Second, passwork.WithInstrumentation(New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+	)))
+
+	_, err := client.DeleteFolder("f1")
+	require.Error(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, codes.Error, ended[0].Status().Code)
+	assert.Contains(t, ended[0].Attributes(), attribute.String("error.type", "502"))
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+}
@@ -2,6 +2,7 @@ package passwork
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,13 +14,36 @@ import (
 
 // GetPassword Get a password by ID
 func (c *Client) GetPassword(pwId string) (PasswordResponse, error) {
-	url := fmt.Sprintf("%s/items/%s", c.BaseURL, pwId)
-	method := http.MethodGet
+	return c.GetPasswordContext(context.Background(), pwId)
+}
+
+// GetPasswordContext is GetPassword bound to ctx
+func (c *Client) GetPasswordContext(ctx context.Context, pwId string) (PasswordResponse, error) {
+	call := &Call{
+		Operation: "GetPassword",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/items/%s", c.BaseURL, pwId),
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (PasswordResponse, error) {
+		return c.getPassword(ctx, call, pwId)
+	})
+}
+
+func (c *Client) getPassword(ctx context.Context, call *Call, pwId string) (PasswordResponse, error) {
 	var responseObject PasswordResponse
 	var err error
 
+	if c.cacheLookup(cacheKindPassword, pwId, &responseObject) {
+		return responseObject, nil
+	}
+
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, nil)
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if errors.Is(err, ErrOffline) {
+		if data, ok := c.offline.password(pwId); ok {
+			return PasswordResponse{Status: "success", Data: data, Stale: true}, nil
+		}
+	}
 	if err != nil {
 		return responseObject, err
 	}
@@ -31,19 +55,35 @@ func (c *Client) GetPassword(pwId string) (PasswordResponse, error) {
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheStore(cacheKindPassword, pwId, responseObject)
+	c.offlineStore(func(s *OfflineSnapshot) error { return s.storePassword(responseObject.Data) })
+
 	return responseObject, nil
 }
 
 // SearchPassword Search for password by name
 func (c *Client) SearchPassword(request PasswordSearchRequest) (PasswordSearchResponse, error) {
-	baseURL := fmt.Sprintf("%s/items/search", c.BaseURL)
-	method := http.MethodGet
-	var responseObject PasswordSearchResponse
-	var err error
+	return c.SearchPasswordContext(context.Background(), request)
+}
 
+// SearchPasswordContext is SearchPassword bound to ctx
+func (c *Client) SearchPasswordContext(ctx context.Context, request PasswordSearchRequest) (PasswordSearchResponse, error) {
+	call := &Call{
+		Operation: "SearchPassword",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/items/search", c.BaseURL),
+		Request:   &request,
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (PasswordSearchResponse, error) {
+		return c.searchPassword(ctx, call)
+	})
+}
+
+// searchPasswordURL encodes the search request into query parameters of baseURL
+func searchPasswordURL(baseURL string, request PasswordSearchRequest) string {
 	// Build query parameters
 	params := make([]string, 0)
 	if request.Query != "" {
@@ -67,13 +107,25 @@ func (c *Client) SearchPassword(request PasswordSearchRequest) (PasswordSearchRe
 	}
 
 	// Add query parameters to URL if any
-	url := baseURL
 	if len(params) > 0 {
-		url = fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
+		return fmt.Sprintf("%s?%s", baseURL, strings.Join(params, "&"))
 	}
+	return baseURL
+}
 
-	// HTTP request
-	response, _, err := c.sendRequest(method, url, nil)
+func (c *Client) searchPassword(ctx context.Context, call *Call) (PasswordSearchResponse, error) {
+	var responseObject PasswordSearchResponse
+
+	request, err := callRequest[PasswordSearchRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// HTTP request, encoding the request as the interceptors left it
+	response, _, err := c.sendRequestContext(ctx, call.Method, searchPasswordURL(call.URL, request), nil)
+	if errors.Is(err, ErrOffline) {
+		return PasswordSearchResponse{Status: "success", Data: c.offline.search(request), Stale: true}, nil
+	}
 	if err != nil {
 		return responseObject, err
 	}
@@ -87,25 +139,45 @@ func (c *Client) SearchPassword(request PasswordSearchRequest) (PasswordSearchRe
 	// Check status only if it's present (API v4 format)
 	// API v1 doesn't return Status field
 	if responseObject.Status != "" && responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.offlineStore(func(s *OfflineSnapshot) error { return s.storeSearch(request, responseObject.Data) })
+
 	return responseObject, nil
 }
 
 func (c *Client) AddPassword(pwRequest PasswordRequest) (PasswordResponse, error) {
-	url := fmt.Sprintf("%s/items", c.BaseURL)
-	method := http.MethodPost
+	return c.addPasswordContext(context.Background(), pwRequest)
+}
+
+func (c *Client) addPasswordContext(ctx context.Context, pwRequest PasswordRequest) (PasswordResponse, error) {
+	call := &Call{
+		Operation: "AddPassword",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/items", c.BaseURL),
+		Request:   &pwRequest,
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (PasswordResponse, error) {
+		return c.addPassword(ctx, call)
+	})
+}
+
+func (c *Client) addPassword(ctx context.Context, call *Call) (PasswordResponse, error) {
 	var responseObject PasswordResponse
-	var err error
 
-	body, err := json.Marshal(pwRequest)
+	pwRequest, err := callRequest[PasswordRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
+	body, err := json.Marshal(pwRequest.wire())
 	if err != nil {
 		return responseObject, err
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -117,24 +189,46 @@ func (c *Client) AddPassword(pwRequest PasswordRequest) (PasswordResponse, error
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheInvalidate(cacheKindVault, pwRequest.VaultId)
+	c.cacheInvalidate(cacheKindFolder, pwRequest.FolderId)
+
 	return responseObject, nil
 }
 
 func (c *Client) EditPassword(pwId string, request PasswordRequest) (PasswordResponse, error) {
-	url := fmt.Sprintf("%s/items/%s", c.BaseURL, pwId)
-	method := http.MethodPut
+	return c.editPasswordContext(context.Background(), pwId, request)
+}
+
+func (c *Client) editPasswordContext(ctx context.Context, pwId string, request PasswordRequest) (PasswordResponse, error) {
+	call := &Call{
+		Operation: "EditPassword",
+		Method:    http.MethodPut,
+		URL:       fmt.Sprintf("%s/items/%s", c.BaseURL, pwId),
+		Request:   &request,
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (PasswordResponse, error) {
+		return c.editPassword(ctx, call, pwId)
+	})
+}
+
+func (c *Client) editPassword(ctx context.Context, call *Call, pwId string) (PasswordResponse, error) {
 	var responseObject PasswordResponse
 
-	body, err := json.Marshal(request)
+	request, err := callRequest[PasswordRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
+	body, err := json.Marshal(request.wire())
 	if err != nil {
 		return responseObject, err
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -146,19 +240,34 @@ func (c *Client) EditPassword(pwId string, request PasswordRequest) (PasswordRes
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheInvalidate(cacheKindPassword, pwId)
+
 	return responseObject, nil
 }
 
 func (c *Client) DeletePassword(pwId string) (DeleteResponse, error) {
-	url := fmt.Sprintf("%s/items/%s", c.BaseURL, pwId)
-	method := http.MethodDelete
+	return c.deletePasswordContext(context.Background(), pwId)
+}
+
+func (c *Client) deletePasswordContext(ctx context.Context, pwId string) (DeleteResponse, error) {
+	call := &Call{
+		Operation: "DeletePassword",
+		Method:    http.MethodDelete,
+		URL:       fmt.Sprintf("%s/items/%s", c.BaseURL, pwId),
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (DeleteResponse, error) {
+		return c.deletePassword(ctx, call, pwId)
+	})
+}
+
+func (c *Client) deletePassword(ctx context.Context, call *Call, pwId string) (DeleteResponse, error) {
 	var responseObject DeleteResponse
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, nil)
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
 	if err != nil {
 		return responseObject, err
 	}
@@ -170,7 +279,51 @@ func (c *Client) DeletePassword(pwId string) (DeleteResponse, error) {
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	c.cacheInvalidate(cacheKindPassword, pwId)
+	c.cacheInvalidateKind(cacheKindVault, cacheKindFolder)
+	c.offlineUpdate(func(s *OfflineSnapshot) error { return s.removePassword(pwId) })
+
+	return responseObject, nil
+}
+
+// GetAttachment Get an attachment of a password including its encrypted data
+func (c *Client) GetAttachment(pwId string, attachmentId string) (PasswordAttachmentResponse, error) {
+	return c.GetAttachmentContext(context.Background(), pwId, attachmentId)
+}
+
+// GetAttachmentContext is GetAttachment bound to ctx
+func (c *Client) GetAttachmentContext(ctx context.Context, pwId string, attachmentId string) (PasswordAttachmentResponse, error) {
+	call := &Call{
+		Operation: "GetAttachment",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/items/%s/attachments/%s", c.BaseURL, pwId, attachmentId),
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (PasswordAttachmentResponse, error) {
+		return c.getAttachment(ctx, call, pwId, attachmentId)
+	})
+}
+
+func (c *Client) getAttachment(ctx context.Context, call *Call, pwId string, attachmentId string) (PasswordAttachmentResponse, error) {
+	var responseObject PasswordAttachmentResponse
+	var err error
+
+	// HTTP request
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[PasswordAttachmentResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	if responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
 	return responseObject, nil
@@ -6,12 +6,14 @@ type PasswordResponse struct {
 	Status string
 	Code   string // passwordNull, accessDenied
 	Data   PasswordResponseData
+	Stale  bool `json:"-"` // Served from the offline snapshot
 }
 
 type PasswordSearchResponse struct {
 	Status string
 	Code   string
 	Data   []PasswordResponseData
+	Stale  bool `json:"-"` // Served from the offline snapshot
 }
 
 // UnmarshalJSON implements custom unmarshaling to support both API v1 and v4 formats
@@ -43,8 +45,8 @@ type PasswordResponseData struct {
 	Id                 string
 	Name               string
 	Login              string
-	CryptedPassword    string
-	CryptedKey         string
+	CryptedPassword    Secret
+	CryptedKey         Secret
 	Description        string
 	Url                string
 	Color              int
@@ -70,7 +72,7 @@ type PasswordSearchRequest struct {
 type PasswordRequest struct {
 	Name            string                   `json:"name"`
 	Login           string                   `json:"login,omitempty"`
-	CryptedPassword string                   `json:"cryptedPassword,omitempty"`
+	CryptedPassword Secret                   `json:"cryptedPassword,omitempty"`
 	Url             string                   `json:"url,omitempty"`
 	Description     string                   `json:"description,omitempty"`
 	Custom          []PasswordCustomData     `json:"custom,omitempty"`
@@ -84,11 +86,36 @@ type PasswordRequest struct {
 }
 
 type PasswordCustomData struct {
+	Name  string `json:"name,omitempty"`
+	Value Secret `json:"value,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+// Equal reports whether both fields have the same name, type and value
+func (c PasswordCustomData) Equal(other PasswordCustomData) bool {
+	return c.Name == other.Name && c.Type == other.Type && c.Value.Equal(other.Value)
+}
+
+type passwordCustomWire struct {
 	Name  string `json:"name,omitempty"`
 	Value string `json:"value,omitempty"`
 	Type  string `json:"type,omitempty"`
 }
 
+// wire returns the request as sent to the API, with secrets revealed
+func (r PasswordRequest) wire() any {
+	type alias PasswordRequest
+	custom := make([]passwordCustomWire, len(r.Custom))
+	for i, c := range r.Custom {
+		custom[i] = passwordCustomWire{Name: c.Name, Value: c.Value.Reveal(), Type: c.Type}
+	}
+	return struct {
+		alias
+		CryptedPassword string               `json:"cryptedPassword,omitempty"`
+		Custom          []passwordCustomWire `json:"custom,omitempty"`
+	}{alias(r), r.CryptedPassword.Reveal(), custom}
+}
+
 type PasswordAttachmentData struct {
 	Name          string `json:"name,omitempty"`
 	Id            string `json:"id,omitempty"`
@@ -104,5 +131,11 @@ type PasswordShortcutData struct {
 	FolderId   string
 	Access     string
 	AccessCode int
-	CryptedKey string
+	CryptedKey Secret
+}
+
+type PasswordAttachmentResponse struct {
+	Status string
+	Code   string
+	Data   PasswordAttachmentData
 }
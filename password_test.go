@@ -6,7 +6,7 @@ func (suite *PassworkTestSuite) TestPassword() {
 			Name:            "provider-test-entry",
 			VaultId:         suite.VaultId,
 			Login:           "provider-test-user",
-			CryptedPassword: "cHJvdmlkZXItdGVzdC1wYXNzd29yZA==",
+			CryptedPassword: NewSecret("cHJvdmlkZXItdGVzdC1wYXNzd29yZA=="),
 			Description:     "provider-test-description",
 			Url:             "https://login.com",
 			Color:           1,
@@ -21,7 +21,7 @@ func (suite *PassworkTestSuite) TestPassword() {
 			suite.Equal("provider-test-entry", result.Data.Name, "Result name should be the same as request name.")
 			suite.Equal(suite.VaultId, result.Data.VaultId, "Result VaultId should be the same as request VaultId.")
 			suite.Equal("provider-test-user", result.Data.Login, "Result Login should be the same as request Login.")
-			suite.Equal("cHJvdmlkZXItdGVzdC1wYXNzd29yZA==", result.Data.CryptedPassword, "Result CryptedPassword should be the same as request CryptedPassword.")
+			suite.Equal("cHJvdmlkZXItdGVzdC1wYXNzd29yZA==", result.Data.CryptedPassword.Reveal(), "Result CryptedPassword should be the same as request CryptedPassword.")
 			suite.Equal("provider-test-description", result.Data.Description, "Result Description should be the same as request Description.")
 			suite.Equal("https://login.com", result.Data.Url, "Result Url should be the same as request Url.")
 			suite.Equal(1, result.Data.Color, "Result Color should be the same as request Color.")
@@ -35,7 +35,7 @@ func (suite *PassworkTestSuite) TestPassword() {
 			Name:            "provider-test-entry-changed",
 			VaultId:         suite.VaultId,
 			Login:           "provider-test-user-changed",
-			CryptedPassword: "cHJvdmlkZXItdGVzdC1wYXNzd29yZC1jaGFuZ2Vk",
+			CryptedPassword: NewSecret("cHJvdmlkZXItdGVzdC1wYXNzd29yZC1jaGFuZ2Vk"),
 			Description:     "provider-test-description-changed",
 			Url:             "https://login-changed.com",
 			Color:           2,
@@ -50,7 +50,7 @@ func (suite *PassworkTestSuite) TestPassword() {
 		suite.Equal("provider-test-entry-changed", result.Data.Name, "Result name should be the same as request name.")
 		suite.Equal(suite.VaultId, result.Data.VaultId, "Result VaultId should be the same as request VaultId.")
 		suite.Equal("provider-test-user-changed", result.Data.Login, "Result Login should be the same as request Login.")
-		suite.Equal("cHJvdmlkZXItdGVzdC1wYXNzd29yZC1jaGFuZ2Vk", result.Data.CryptedPassword, "Result CryptedPassword should be the same as request CryptedPassword.")
+		suite.Equal("cHJvdmlkZXItdGVzdC1wYXNzd29yZC1jaGFuZ2Vk", result.Data.CryptedPassword.Reveal(), "Result CryptedPassword should be the same as request CryptedPassword.")
 		suite.Equal("provider-test-description-changed", result.Data.Description, "Result Description should be the same as request Description.")
 		suite.Equal("https://login-changed.com", result.Data.Url, "Result Url should be the same as request Url.")
 		suite.Equal(2, result.Data.Color, "Result Color should be the same as request Color.")
@@ -88,7 +88,7 @@ func (suite *PassworkTestSuite) TestPassword() {
 		suite.Equal("provider-test-entry-changed", result.Data.Name, "Result name should be the same as request name.")
 		suite.Equal(suite.VaultId, result.Data.VaultId, "Result VaultId should be the same as request VaultId.")
 		suite.Equal("provider-test-user-changed", result.Data.Login, "Result Login should be the same as request Login.")
-		suite.Equal("cHJvdmlkZXItdGVzdC1wYXNzd29yZC1jaGFuZ2Vk", result.Data.CryptedPassword, "Result CryptedPassword should be the same as request CryptedPassword.")
+		suite.Equal("cHJvdmlkZXItdGVzdC1wYXNzd29yZC1jaGFuZ2Vk", result.Data.CryptedPassword.Reveal(), "Result CryptedPassword should be the same as request CryptedPassword.")
 		suite.Equal("provider-test-description-changed", result.Data.Description, "Result Description should be the same as request Description.")
 		suite.Equal("https://login-changed.com", result.Data.Url, "Result Url should be the same as request Url.")
 		suite.Equal(2, result.Data.Color, "Result Color should be the same as request Color.")
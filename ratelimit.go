@@ -0,0 +1,168 @@
+package passwork
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket shared by all requests of a Client. It slows
+// down when the server answers with 429 Too Many Requests and recovers to the
+// configured rate on successful responses.
+type RateLimiter struct {
+	mu           sync.Mutex
+	limit        float64 // Configured requests per second
+	rate         float64 // Current requests per second
+	burst        int
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	throttled    int // Number of 429 responses seen
+}
+
+type RateLimitState struct {
+	Limit        float64 // Configured requests per second
+	Rate         float64 // Current requests per second after adapting to the server
+	Burst        int
+	Tokens       float64   // Requests that can be sent immediately
+	BlockedUntil time.Time // Set while the server asked to pause
+	Throttled    int       // Number of 429 responses seen
+}
+
+const minRateFactor = 0.05
+
+// NewRateLimiter creates a limiter allowing rps requests per second with bursts of burst requests
+// A rps of 0 only honors pauses requested by the server.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	if rps <= 0 {
+		rps = math.Inf(1)
+	}
+	return &RateLimiter{
+		limit:  rps,
+		rate:   rps,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refill(now)
+
+		var wait time.Duration
+		switch {
+		case now.Before(l.blockedUntil):
+			wait = l.blockedUntil.Sub(now)
+		case l.tokens >= 1:
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		default:
+			wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Observe adapts the limiter to a server response
+func (l *RateLimiter) Observe(resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.throttled++
+		l.rate = max(l.rate/2, l.limit*minRateFactor)
+		l.tokens = 0
+
+		pause := retryAfter(resp.Header, now)
+		if pause <= 0 {
+			pause = time.Duration(float64(time.Second) / l.rate)
+		}
+		if until := now.Add(pause); until.After(l.blockedUntil) {
+			l.blockedUntil = until
+		}
+		return
+	}
+
+	// The server announces an exhausted quota before rejecting requests
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset := rateLimitReset(resp.Header, now); reset.After(l.blockedUntil) {
+			l.blockedUntil = reset
+		}
+	}
+
+	if resp.StatusCode < 400 && l.rate < l.limit {
+		l.refill(now)
+		l.rate = min(l.rate+l.limit*0.1, l.limit)
+	}
+}
+
+// State returns a snapshot of the limiter
+func (l *RateLimiter) State() RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+
+	return RateLimitState{
+		Limit:        l.limit,
+		Rate:         l.rate,
+		Burst:        l.burst,
+		Tokens:       l.tokens,
+		BlockedUntil: l.blockedUntil,
+		Throttled:    l.throttled,
+	}
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	if elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed*l.rate, float64(l.burst))
+	}
+}
+
+// retryAfter parses the Retry-After header given in seconds or as HTTP date
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// rateLimitReset parses X-RateLimit-Reset given as unix timestamp or as seconds from now
+func rateLimitReset(header http.Header, now time.Time) time.Time {
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset <= 0 {
+		return time.Time{}
+	}
+	if reset > now.Unix()-86400 {
+		return time.Unix(reset, 0)
+	}
+	return now.Add(time.Duration(reset) * time.Second)
+}
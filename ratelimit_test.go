@@ -0,0 +1,95 @@
+package passwork
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(20, 5)
+	start := time.Now()
+
+	for range 10 {
+		assert.NoError(t, limiter.Wait(context.Background()))
+	}
+
+	// 5 requests burst, the other 5 are spread at 20 per second
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestRateLimiterAdapts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", time.Second, WithRateLimit(100, 10))
+
+	_, status, err := client.sendRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+
+	state, ok := client.RateLimitState()
+	assert.True(t, ok)
+	assert.Equal(t, 1, state.Throttled)
+	assert.Equal(t, 50.0, state.Rate, "The rate should be halved after a 429 response.")
+	assert.WithinDuration(t, time.Now().Add(time.Second), state.BlockedUntil, 100*time.Millisecond)
+
+	// Concurrent requests all wait for the pause requested by the server
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.sendRequest(http.MethodGet, server.URL, nil)
+		}()
+	}
+	wg.Wait()
+
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	state, _ = client.RateLimitState()
+	assert.Greater(t, state.Rate, 50.0, "The rate should recover after successful responses.")
+}
+
+func TestRateLimitReset(t *testing.T) {
+	limiter := NewRateLimiter(100, 10)
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "2")
+
+	limiter.Observe(&http.Response{StatusCode: http.StatusOK, Header: header})
+
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), limiter.State().BlockedUntil, 100*time.Millisecond)
+}
+
+func TestNoRateLimiter(t *testing.T) {
+	_, ok := NewClient("http://localhost", "key", time.Second).RateLimitState()
+
+	assert.False(t, ok)
+}
@@ -0,0 +1,120 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+// Policy selects items of a vault and the rotator used for them.
+// All given criteria must match, tags match if the item has any of them.
+type Policy struct {
+	Name      string
+	VaultId   string
+	Tags      []string
+	FolderIds []string      // Items in these folders or their subfolders
+	MaxAge    time.Duration // Items whose password is older, items without LastPasswordUpdate are skipped
+	Rotator   Rotator
+}
+
+// Matches reports whether the policy selects item at time now
+func (p Policy) Matches(item passwork.PasswordResponseData, now time.Time) bool {
+	if p.VaultId != "" && item.VaultId != p.VaultId {
+		return false
+	}
+	if len(p.Tags) > 0 && !slices.ContainsFunc(p.Tags, func(tag string) bool { return slices.Contains(item.Tags, tag) }) {
+		return false
+	}
+	if len(p.FolderIds) > 0 && !inFolders(p.FolderIds, item) {
+		return false
+	}
+	if p.MaxAge > 0 {
+		if item.LastPasswordUpdate == 0 {
+			return false
+		}
+		if now.Sub(time.Unix(int64(item.LastPasswordUpdate), 0)) <= p.MaxAge {
+			return false
+		}
+	}
+	return true
+}
+
+func inFolders(folderIds []string, item passwork.PasswordResponseData) bool {
+	if slices.Contains(folderIds, item.FolderId) {
+		return true
+	}
+	return slices.ContainsFunc(item.Path, func(segment passwork.PathData) bool {
+		return segment.Type == "folder" && slices.Contains(folderIds, segment.Id)
+	})
+}
+
+// Select returns the items of the policy's vault it matches
+func Select(ctx context.Context, client *passwork.Client, policy Policy) ([]passwork.PasswordResponseData, error) {
+	if policy.VaultId == "" {
+		return nil, errors.New("rotation: policy has no vault")
+	}
+
+	items, err := client.Uncached().SearchPassword(passwork.PasswordSearchRequest{VaultId: policy.VaultId})
+	if err != nil {
+		return nil, fmt.Errorf("rotation: list items of vault %s: %w", policy.VaultId, err)
+	}
+
+	now := time.Now()
+	var selected []passwork.PasswordResponseData
+	for _, item := range items.Data {
+		if policy.Matches(item, now) {
+			selected = append(selected, item)
+		}
+	}
+	return selected, nil
+}
+
+type RunOptions struct {
+	// DryRun only reports the selected items as Pending
+	DryRun bool
+}
+
+// Run rotates all items selected by the policies. An item matched by several
+// policies is rotated once, by the first one. Failures don't stop the run.
+func Run(ctx context.Context, client *passwork.Client, policies []Policy, opts RunOptions) ([]Result, error) {
+	var results []Result
+	rotated := make(map[string]bool)
+
+	for _, policy := range policies {
+		if policy.Rotator == nil {
+			return results, fmt.Errorf("rotation: policy %q has no rotator", policy.Name)
+		}
+
+		items, err := Select(ctx, client, policy)
+		if err != nil {
+			return results, err
+		}
+
+		for _, item := range items {
+			if rotated[item.Id] {
+				continue
+			}
+			rotated[item.Id] = true
+
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+
+			var result Result
+			if opts.DryRun {
+				result = Result{ItemId: item.Id, Name: item.Name}
+				result.transition(Pending)
+			} else {
+				result = Rotate(ctx, client, item.Id, policy.Rotator)
+			}
+			result.Policy = policy.Name
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,121 @@
+package rotation
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+type State string
+
+const (
+	Pending    State = "pending"
+	Generated  State = "generated"
+	Applied    State = "applied"
+	Verified   State = "verified"
+	Committed  State = "committed"   // Rotation finished
+	Failed     State = "failed"      // Nothing was changed on the target system
+	RolledBack State = "rolled-back" // The target system was restored to the previous secret
+	Broken     State = "broken"      // Rollback failed, the target system and Passwork may disagree
+)
+
+// Result is the outcome of rotating a single item
+type Result struct {
+	ItemId string
+	Name   string
+	Policy string
+	State  State
+	States []State // All states passed through, starting with Pending
+	Err    error
+}
+
+func (r *Result) transition(state State) {
+	r.State = state
+	r.States = append(r.States, state)
+}
+
+// Rotate rotates a single item with rotator
+func Rotate(ctx context.Context, client *passwork.Client, itemId string, rotator Rotator) Result {
+	result := Result{ItemId: itemId}
+	result.transition(Pending)
+
+	item, err := client.Uncached().GetPassword(itemId)
+	if err != nil {
+		return fail(result, fmt.Errorf("rotation: get item: %w", err))
+	}
+	result.Name = item.Data.Name
+
+	password, err := base64.StdEncoding.DecodeString(item.Data.CryptedPassword.Reveal())
+	if err != nil {
+		return fail(result, fmt.Errorf("rotation: decode password: %w", err))
+	}
+	target := Target{Item: item.Data, Password: string(password)}
+
+	secret, err := rotator.Generate(ctx, target)
+	if err != nil {
+		return fail(result, fmt.Errorf("rotation: generate: %w", err))
+	}
+	if secret == "" {
+		return fail(result, errors.New("rotation: generated secret is empty"))
+	}
+	result.transition(Generated)
+
+	if err := ctx.Err(); err != nil {
+		return fail(result, err)
+	}
+
+	// A failed Apply may have partially changed the target system
+	if err := rotator.Apply(ctx, target, secret); err != nil {
+		return rollback(ctx, result, rotator, target, fmt.Errorf("rotation: apply: %w", err))
+	}
+	result.transition(Applied)
+
+	if err := rotator.Verify(ctx, target, secret); err != nil {
+		return rollback(ctx, result, rotator, target, fmt.Errorf("rotation: verify: %w", err))
+	}
+	result.transition(Verified)
+
+	if _, err := client.EditPassword(itemId, editRequest(item.Data, secret)); err != nil {
+		return rollback(ctx, result, rotator, target, fmt.Errorf("rotation: commit: %w", err))
+	}
+	result.transition(Committed)
+
+	return result
+}
+
+func fail(result Result, err error) Result {
+	result.transition(Failed)
+	result.Err = err
+	return result
+}
+
+func rollback(ctx context.Context, result Result, rotator Rotator, target Target, err error) Result {
+	// Roll back even if ctx is done, the target system must not keep a secret Passwork doesn't know
+	if rollbackErr := rotator.Rollback(context.WithoutCancel(ctx), target); rollbackErr != nil {
+		result.transition(Broken)
+		result.Err = errors.Join(err, fmt.Errorf("rotation: rollback: %w", rollbackErr))
+		return result
+	}
+	result.transition(RolledBack)
+	result.Err = err
+	return result
+}
+
+// editRequest keeps all fields of the item and replaces the password
+func editRequest(item passwork.PasswordResponseData, secret string) passwork.PasswordRequest {
+	return passwork.PasswordRequest{
+		Name:            item.Name,
+		Login:           item.Login,
+		CryptedPassword: passwork.NewSecret(base64.StdEncoding.EncodeToString([]byte(secret))),
+		Url:             item.Url,
+		Description:     item.Description,
+		Custom:          item.Custom,
+		Color:           item.Color,
+		Tags:            item.Tags,
+		VaultId:         item.VaultId,
+		FolderId:        item.FolderId,
+	}
+}
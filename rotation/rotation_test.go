@@ -0,0 +1,220 @@
+package rotation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+	"github.com/treasure33/passwork-client-go/generator"
+)
+
+type rotationTestServer struct {
+	mu         sync.Mutex
+	items      map[string]passwork.PasswordResponseData
+	edits      map[string]passwork.PasswordRequest
+	failCommit bool
+}
+
+func newRotationTestServer(t *testing.T) (*passwork.Client, *rotationTestServer) {
+	old := int(time.Now().Add(-100 * 24 * time.Hour).Unix())
+	fresh := int(time.Now().Add(-time.Hour).Unix())
+	state := &rotationTestServer{
+		items: map[string]passwork.PasswordResponseData{
+			"pw1": {Id: "pw1", VaultId: "v1", FolderId: "f1", Name: "database", Login: "admin", Tags: []string{"rotate"},
+				CryptedPassword: passwork.NewSecret(base64.StdEncoding.EncodeToString([]byte("old-secret"))), LastPasswordUpdate: old},
+			"pw2": {Id: "pw2", VaultId: "v1", FolderId: "f2", Name: "mail", Tags: []string{"rotate"},
+				CryptedPassword: passwork.NewSecret(base64.StdEncoding.EncodeToString([]byte("mail-secret"))), LastPasswordUpdate: fresh,
+				Path: []passwork.PathData{{Type: "folder", Id: "f1"}, {Type: "folder", Id: "f2"}}},
+			"pw3": {Id: "pw3", VaultId: "v1", Name: "untagged", LastPasswordUpdate: old},
+		},
+		edits: make(map[string]passwork.PasswordRequest),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		if r.URL.Path == "/items/search" {
+			items := make([]map[string]any, 0, len(state.items))
+			for _, item := range state.items {
+				items = append(items, revealed(item))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": items})
+			return
+		}
+
+		id := r.URL.Path[len("/items/"):]
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": revealed(state.items[id])})
+		case http.MethodPut:
+			if state.failCommit {
+				w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+				return
+			}
+			var request passwork.PasswordRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			state.edits[id] = request
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": revealed(state.items[id])})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return passwork.NewClient(server.URL, "key", time.Second), state
+}
+
+type fakeRotator struct {
+	applyErr, verifyErr, rollbackErr error
+	calls                            []string
+	previous                         string
+}
+
+func (r *fakeRotator) Generate(ctx context.Context, target Target) (string, error) {
+	r.calls = append(r.calls, "generate")
+	return "new-secret", nil
+}
+
+func (r *fakeRotator) Apply(ctx context.Context, target Target, secret string) error {
+	r.calls = append(r.calls, "apply")
+	return r.applyErr
+}
+
+func (r *fakeRotator) Verify(ctx context.Context, target Target, secret string) error {
+	r.calls = append(r.calls, "verify")
+	return r.verifyErr
+}
+
+func (r *fakeRotator) Rollback(ctx context.Context, target Target) error {
+	r.calls = append(r.calls, "rollback")
+	r.previous = target.Password
+	return r.rollbackErr
+}
+
+func TestRotate(t *testing.T) {
+	client, state := newRotationTestServer(t)
+
+	rotator := &fakeRotator{}
+	result := Rotate(context.Background(), client, "pw1", rotator)
+	require.NoError(t, result.Err)
+	assert.Equal(t, Committed, result.State)
+	assert.Equal(t, []State{Pending, Generated, Applied, Verified, Committed}, result.States)
+	assert.Equal(t, []string{"generate", "apply", "verify"}, rotator.calls)
+
+	edit := state.edits["pw1"]
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("new-secret")), edit.CryptedPassword.Reveal())
+	assert.Equal(t, "admin", edit.Login)
+	assert.Equal(t, "f1", edit.FolderId)
+	assert.Equal(t, []string{"rotate"}, edit.Tags)
+}
+
+func TestRotateRollback(t *testing.T) {
+	client, state := newRotationTestServer(t)
+
+	t.Run("Verify", func(t *testing.T) {
+		rotator := &fakeRotator{verifyErr: errors.New("login failed")}
+		result := Rotate(context.Background(), client, "pw1", rotator)
+		assert.Equal(t, RolledBack, result.State)
+		assert.ErrorContains(t, result.Err, "login failed")
+		assert.Equal(t, "old-secret", rotator.previous)
+		assert.Empty(t, state.edits)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		state.failCommit = true
+		defer func() { state.failCommit = false }()
+
+		rotator := &fakeRotator{}
+		result := Rotate(context.Background(), client, "pw1", rotator)
+		assert.Equal(t, []State{Pending, Generated, Applied, Verified, RolledBack}, result.States)
+		assert.ErrorContains(t, result.Err, "accessDenied")
+		assert.Equal(t, []string{"generate", "apply", "verify", "rollback"}, rotator.calls)
+	})
+
+	t.Run("Broken", func(t *testing.T) {
+		rotator := &fakeRotator{applyErr: errors.New("timeout"), rollbackErr: errors.New("unreachable")}
+		result := Rotate(context.Background(), client, "pw1", rotator)
+		assert.Equal(t, Broken, result.State)
+		assert.ErrorContains(t, result.Err, "timeout")
+		assert.ErrorContains(t, result.Err, "unreachable")
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	client, state := newRotationTestServer(t)
+	ctx := context.Background()
+
+	selected, err := Select(ctx, client, Policy{VaultId: "v1", Tags: []string{"rotate"}, MaxAge: 90 * 24 * time.Hour})
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Equal(t, "pw1", selected[0].Id)
+
+	selected, err = Select(ctx, client, Policy{VaultId: "v1", FolderIds: []string{"f1"}})
+	require.NoError(t, err)
+	assert.Len(t, selected, 2, "items of subfolders are selected")
+
+	policies := []Policy{
+		{Name: "tagged", VaultId: "v1", Tags: []string{"rotate"}, Rotator: PasswordRotator{}},
+		{Name: "all", VaultId: "v1", Rotator: PasswordRotator{}},
+	}
+
+	results, err := Run(ctx, client, policies, RunOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Empty(t, state.edits)
+
+	results, err = Run(ctx, client, policies, RunOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	byPolicy := make(map[string]int)
+	for _, result := range results {
+		assert.Equal(t, Committed, result.State)
+		byPolicy[result.Policy]++
+	}
+	assert.Equal(t, map[string]int{"tagged": 2, "all": 1}, byPolicy)
+	assert.Len(t, state.edits, 3)
+}
+
+func TestPasswordRotator(t *testing.T) {
+	rotator := PasswordRotator{Length: 32}
+	first, err := rotator.Generate(context.Background(), Target{})
+	require.NoError(t, err)
+	second, err := rotator.Generate(context.Background(), Target{})
+	require.NoError(t, err)
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+	assert.NotRegexp(t, `[0O1lI]`, first)
+
+	password, err := PasswordRotator{}.Generate(context.Background(), Target{})
+	require.NoError(t, err)
+	assert.Len(t, password, 24)
+
+	password, err = PasswordRotator{Length: 12, Policy: generator.Policy{Digits: true}}.Generate(context.Background(), Target{})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{12}$`, password)
+}
+
+// revealed encodes an item the way the API sends it, with secrets in clear
+func revealed(item passwork.PasswordResponseData) map[string]any {
+	data, _ := json.Marshal(item)
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+
+	fields["CryptedPassword"] = item.CryptedPassword.Reveal()
+	custom := make([]map[string]string, len(item.Custom))
+	for i, c := range item.Custom {
+		custom[i] = map[string]string{"name": c.Name, "value": c.Value.Reveal(), "type": c.Type}
+	}
+	fields["Custom"] = custom
+	return fields
+}
@@ -0,0 +1,62 @@
+// Package rotation rotates secrets stored in Passwork and on the systems they belong to.
+package rotation
+
+import (
+	"context"
+
+	passwork "github.com/treasure33/passwork-client-go"
+	"github.com/treasure33/passwork-client-go/generator"
+)
+
+// Target is the item being rotated with its current decrypted password
+type Target struct {
+	Item     passwork.PasswordResponseData
+	Password string
+}
+
+// Rotator changes the secret of a target system. Rotate calls Generate, Apply
+// and Verify in order and commits the new secret to Passwork afterwards.
+// Rollback restores the previous secret if Verify or the commit fail.
+type Rotator interface {
+	Generate(ctx context.Context, target Target) (string, error)
+	Apply(ctx context.Context, target Target, secret string) error
+	Verify(ctx context.Context, target Target, secret string) error
+	Rollback(ctx context.Context, target Target) error
+}
+
+// defaultPasswordPolicy generates 24 characters without ambiguous ones
+var defaultPasswordPolicy = generator.Policy{Length: 24, ExcludeAmbiguous: true, SymbolSet: "!#$%&*+-=?@^_"}
+
+// PasswordRotator only regenerates the password stored in Passwork,
+// for secrets that are changed on the target system by other means
+type PasswordRotator struct {
+	// Length of generated passwords, defaults to 24. Overrides the length of Policy.
+	Length int
+
+	// Policy of generated passwords, see generator.ForVault to follow the vault's policy.
+	// The zero value generates passwords without ambiguous characters.
+	Policy generator.Policy
+}
+
+func (r PasswordRotator) Generate(ctx context.Context, target Target) (string, error) {
+	policy := r.Policy
+	if policy == (generator.Policy{}) {
+		policy = defaultPasswordPolicy
+	}
+	if r.Length > 0 {
+		policy.Length = r.Length
+	}
+	return generator.Password(policy)
+}
+
+func (PasswordRotator) Apply(ctx context.Context, target Target, secret string) error {
+	return nil
+}
+
+func (PasswordRotator) Verify(ctx context.Context, target Target, secret string) error {
+	return nil
+}
+
+func (PasswordRotator) Rollback(ctx context.Context, target Target) error {
+	return nil
+}
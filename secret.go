@@ -0,0 +1,111 @@
+package passwork
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret holds a secret value such as a password. It prints and marshals to
+// JSON as [REDACTED], Reveal returns the value. Copies share the underlying
+// bytes, Destroy wipes them for all copies.
+type Secret struct {
+	value *[]byte
+}
+
+// NewSecret creates a secret holding value
+func NewSecret(value string) Secret {
+	return SecretFromBytes([]byte(value))
+}
+
+// SecretFromBytes creates a secret that takes ownership of b, which is wiped on Destroy
+func SecretFromBytes(b []byte) Secret {
+	if len(b) == 0 {
+		return Secret{}
+	}
+	return Secret{value: &b}
+}
+
+// Reveal returns the secret value
+func (s Secret) Reveal() string {
+	return string(s.Bytes())
+}
+
+// Bytes returns the secret value without copying it, it must not be modified or retained
+func (s Secret) Bytes() []byte {
+	if s.value == nil {
+		return nil
+	}
+	return *s.value
+}
+
+// IsEmpty reports whether the secret holds no value or was destroyed
+func (s Secret) IsEmpty() bool {
+	return len(s.Bytes()) == 0
+}
+
+// Equal reports whether both secrets hold the same value
+func (s Secret) Equal(other Secret) bool {
+	return string(s.Bytes()) == string(other.Bytes())
+}
+
+// Destroy overwrites the value with zeros and empties the secret
+func (s Secret) Destroy() {
+	if s.value == nil {
+		return
+	}
+	clear(*s.value)
+	*s.value = nil
+}
+
+func (s Secret) String() string {
+	if s.IsEmpty() {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("passwork.Secret(%q)", s.String())
+}
+
+// Format redacts the value for all verbs, including %+v and %#v
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		fmt.Fprintf(f, "%q", s.String())
+	case 'v':
+		if f.Flag('#') {
+			f.Write([]byte(s.GoString()))
+			return
+		}
+		fallthrough
+	default:
+		f.Write([]byte(s.String()))
+	}
+}
+
+// MarshalJSON redacts the value, requests sent by the client reveal it explicitly
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = NewSecret(value)
+	return nil
+}
+
+// GobEncode keeps the value, gob is only used for the client's encrypted cache and snapshot
+func (s Secret) GobEncode() ([]byte, error) {
+	return append([]byte(nil), s.Bytes()...), nil
+}
+
+func (s *Secret) GobDecode(data []byte) error {
+	*s = SecretFromBytes(append([]byte(nil), data...))
+	return nil
+}
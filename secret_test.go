@@ -0,0 +1,101 @@
+package passwork
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRedacted(t *testing.T) {
+	secret := NewSecret("hunter2")
+	item := PasswordResponseData{Name: "db", CryptedPassword: secret, CryptedKey: NewSecret("key-456"), Custom: []PasswordCustomData{{Name: "token", Value: NewSecret("tok-123")}}}
+	archived := ArchiveItem{Name: "db", Password: NewSecret("hunter2")}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		output := fmt.Sprintf(format, item)
+		assert.NotContains(t, output, "hunter2", format)
+		assert.NotContains(t, output, "key-456", format)
+		assert.NotContains(t, output, "tok-123", format)
+		assert.NotContains(t, fmt.Sprintf(format, archived), "hunter2", format)
+	}
+	assert.Equal(t, redacted, secret.String())
+	assert.Equal(t, `passwork.Secret("[REDACTED]")`, fmt.Sprintf("%#v", secret))
+
+	data, err := json.Marshal(item)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "tok-123")
+
+	assert.Equal(t, "hunter2", secret.Reveal())
+	assert.Equal(t, "", Secret{}.String())
+}
+
+func TestArchiveItemManifest(t *testing.T) {
+	item := ArchiveItem{Name: "db", Password: NewSecret("hunter2"), Custom: []PasswordCustomData{{Name: "token", Value: NewSecret("tok")}}}
+
+	// The manifest is only written into encrypted archives and keeps the values
+	data, err := json.Marshal(item)
+	require.NoError(t, err)
+	var decoded ArchiveItem
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "hunter2", decoded.Password.Reveal())
+	assert.Equal(t, "tok", decoded.Custom[0].Value.Reveal())
+}
+
+func TestSecretDestroy(t *testing.T) {
+	value := []byte("hunter2")
+	secret := SecretFromBytes(value)
+	copied := secret
+
+	secret.Destroy()
+	assert.Equal(t, make([]byte, 7), value, "the bytes are overwritten")
+	assert.True(t, copied.IsEmpty(), "copies share the value")
+	assert.Equal(t, "", copied.Reveal())
+}
+
+func TestSecretEncoding(t *testing.T) {
+	var item PasswordResponseData
+	require.NoError(t, json.Unmarshal([]byte(`{"cryptedPassword":"aHVudGVyMg==","custom":[{"name":"token","value":"tok"}]}`), &item))
+	assert.Equal(t, "aHVudGVyMg==", item.CryptedPassword.Reveal())
+	assert.Equal(t, "tok", item.Custom[0].Value.Reveal())
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(item))
+	var decoded PasswordResponseData
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	assert.True(t, decoded.CryptedPassword.Equal(item.CryptedPassword))
+	assert.True(t, decoded.Custom[0].Equal(item.Custom[0]))
+}
+
+func TestSecretSentToServer(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{"status":"success","data":{"id":"pw1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", time.Second)
+	_, err := client.AddPassword(PasswordRequest{
+		Name:            "db",
+		CryptedPassword: NewSecret("aHVudGVyMg=="),
+		Custom:          []PasswordCustomData{{Name: "token", Value: NewSecret("tok"), Type: "password"}},
+	})
+	require.NoError(t, err)
+
+	var sent map[string]any
+	require.NoError(t, json.Unmarshal(body, &sent))
+	assert.Equal(t, "aHVudGVyMg==", sent["cryptedPassword"])
+	assert.Equal(t, []any{map[string]any{"name": "token", "value": "tok", "type": "password"}}, sent["custom"])
+	assert.Equal(t, "db", sent["name"])
+	assert.NotContains(t, sent, "CryptedPassword")
+}
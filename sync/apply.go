@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+var errFolderMissing = errors.New("sync: folder was not created")
+
+type ApplyOptions struct {
+	// DryRun reports the plan as applied without changing the vault
+	DryRun bool
+}
+
+type ApplyResult struct {
+	Applied []Action
+	Failed  []ActionError
+}
+
+type ActionError struct {
+	Action Action
+	Err    error
+}
+
+func (e ActionError) Error() string {
+	return e.Action.String() + ": " + e.Err.Error()
+}
+
+// Apply executes the plan. Failed actions are collected and the remaining
+// actions still run, except for folders and items whose parent folder
+// couldn't be created.
+// Planning again after a successful apply yields an empty plan.
+func Apply(ctx context.Context, client *passwork.Client, plan Plan, opts ApplyOptions) (ApplyResult, error) {
+	var result ApplyResult
+
+	folderIds := maps.Clone(plan.folderIds)
+	if folderIds == nil {
+		folderIds = make(map[string]string)
+	}
+
+	for _, action := range plan.Actions {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if opts.DryRun {
+			result.Applied = append(result.Applied, action)
+			continue
+		}
+
+		var err error
+		switch {
+		case action.Kind == "folder" && action.Type == Create:
+			parent := action.folder[:len(action.folder)-1]
+			request := passwork.FolderRequest{
+				VaultId:  plan.VaultId,
+				Name:     action.folder[len(action.folder)-1],
+				ParentId: folderIds[strings.Join(parent, "/")],
+			}
+			var response passwork.FolderResponse
+			if len(parent) > 0 && request.ParentId == "" {
+				err = errFolderMissing
+			} else if response, err = client.AddFolder(request); err == nil {
+				folderIds[strings.Join(action.folder, "/")] = response.Data.Id
+			}
+		case action.Kind == "folder" && action.Type == Delete:
+			_, err = client.DeleteFolder(action.Id)
+		case action.Kind == "item" && action.Type == Delete:
+			_, err = client.DeletePassword(action.Id)
+		case action.Kind == "item":
+			request := action.request
+			request.VaultId = plan.VaultId
+			request.FolderId = folderIds[strings.Join(action.folder, "/")]
+			if len(action.folder) > 0 && request.FolderId == "" {
+				err = errFolderMissing
+			} else if action.Type == Create {
+				_, err = client.AddPassword(request)
+			} else {
+				_, err = client.EditPassword(action.Id, request)
+			}
+		}
+
+		if err != nil {
+			result.Failed = append(result.Failed, ActionError{Action: action, Err: err})
+			continue
+		}
+		result.Applied = append(result.Applied, action)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,169 @@
+// Package sync applies a declarative manifest of folders and items to a vault.
+// A manifest is diffed against the live vault into a plan of creates, updates
+// and deletes, which can be reviewed before it is applied.
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+// Manifest is the desired state of a vault. JSON documents are accepted as well
+// since they are valid YAML.
+type Manifest struct {
+	Folders []string   `yaml:"folders" json:"folders"` // Folder paths like "infra/databases"
+	Items   []ItemSpec `yaml:"items" json:"items"`
+}
+
+type ItemSpec struct {
+	Folder      string       `yaml:"folder" json:"folder"`
+	Name        string       `yaml:"name" json:"name"`
+	Login       string       `yaml:"login" json:"login"`
+	Password    SecretRef    `yaml:"password" json:"password"`
+	Url         string       `yaml:"url" json:"url"`
+	Description string       `yaml:"description" json:"description"`
+	Color       int          `yaml:"color" json:"color"`
+	Tags        []string     `yaml:"tags" json:"tags"`
+	Custom      []CustomSpec `yaml:"custom" json:"custom"`
+}
+
+type CustomSpec struct {
+	Name  string    `yaml:"name" json:"name"`
+	Value SecretRef `yaml:"value" json:"value"`
+	Type  string    `yaml:"type" json:"type"`
+}
+
+// SecretRef is a literal value or a reference to an environment variable or a
+// file. In YAML it is either a plain string or a mapping with one of the keys
+// value, env or file.
+type SecretRef struct {
+	Value string `yaml:"value" json:"value"`
+	Env   string `yaml:"env" json:"env"`
+	File  string `yaml:"file" json:"file"`
+}
+
+func (s *SecretRef) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Value = node.Value
+		return nil
+	}
+
+	type plain SecretRef
+	return node.Decode((*plain)(s))
+}
+
+// Resolve returns the secret value. Trailing newlines of files are removed.
+func (s SecretRef) Resolve() (string, error) {
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return value, nil
+	case s.File != "":
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return s.Value, nil
+	}
+}
+
+// LoadManifest parses a YAML or JSON manifest
+func LoadManifest(r io.Reader) (Manifest, error) {
+	var manifest Manifest
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return manifest, fmt.Errorf("sync: parse manifest: %w", err)
+	}
+
+	return manifest, manifest.validate()
+}
+
+func (m Manifest) validate() error {
+	seen := make(map[string]bool)
+	for _, item := range m.Items {
+		if item.Name == "" {
+			return errors.New("sync: item without name")
+		}
+		key := itemKey(splitPath(item.Folder), item.Name)
+		if seen[key] {
+			return fmt.Errorf("sync: duplicate item %s", joinPath(splitPath(item.Folder), item.Name))
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// desiredItem is an item spec with its secrets resolved
+type desiredItem struct {
+	folder  []string
+	request passwork.PasswordRequest
+	secret  string
+}
+
+func (m Manifest) resolve() ([]desiredItem, error) {
+	items := make([]desiredItem, 0, len(m.Items))
+	for _, spec := range m.Items {
+		folder := splitPath(spec.Folder)
+
+		password, err := spec.Password.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("sync: item %s: password: %w", joinPath(folder, spec.Name), err)
+		}
+
+		item := desiredItem{
+			folder: folder,
+			secret: password,
+			request: passwork.PasswordRequest{
+				Name:        spec.Name,
+				Login:       spec.Login,
+				Url:         spec.Url,
+				Description: spec.Description,
+				Color:       spec.Color,
+				Tags:        spec.Tags,
+			},
+		}
+		for _, custom := range spec.Custom {
+			value, err := custom.Value.Resolve()
+			if err != nil {
+				return nil, fmt.Errorf("sync: item %s: custom field %s: %w", joinPath(folder, spec.Name), custom.Name, err)
+			}
+			item.request.Custom = append(item.request.Custom, passwork.PasswordCustomData{Name: custom.Name, Value: passwork.NewSecret(value), Type: custom.Type})
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func joinPath(folder []string, name ...string) string {
+	return strings.Join(append(append([]string{}, folder...), name...), "/")
+}
+
+func itemKey(folder []string, name string) string {
+	return strings.Join(folder, "\x00") + "\x00\x00" + name
+}
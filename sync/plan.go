@@ -0,0 +1,219 @@
+package sync
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+type ActionType string
+
+const (
+	Create ActionType = "create"
+	Update ActionType = "update"
+	Delete ActionType = "delete"
+)
+
+type Action struct {
+	Type ActionType
+	Kind string // folder, item
+	Path string // Folder path, or folder path and item name
+
+	// Id of the live object for updates and deletes
+	Id string
+
+	// Changes lists the names of changed fields of updated items. Secret
+	// fields are named but their values are never part of a plan.
+	Changes []string
+
+	folder  []string
+	request passwork.PasswordRequest
+}
+
+func (a Action) String() string {
+	if len(a.Changes) > 0 {
+		return fmt.Sprintf("%s %s %s (%s)", a.Type, a.Kind, a.Path, strings.Join(a.Changes, ", "))
+	}
+	return fmt.Sprintf("%s %s %s", a.Type, a.Kind, a.Path)
+}
+
+// Plan lists the actions needed to reach the desired state in execution order
+type Plan struct {
+	VaultId string
+	Actions []Action
+
+	// Live folder IDs by path at planning time
+	folderIds map[string]string
+}
+
+// Empty reports whether the vault already matches the manifest
+func (p Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+func (p Plan) String() string {
+	lines := make([]string, len(p.Actions))
+	for i, action := range p.Actions {
+		lines[i] = action.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+type PlanOptions struct {
+	// Prune deletes items and folders of the vault that are not in the manifest
+	Prune bool
+}
+
+// NewPlan diffs the manifest against the live vault
+func NewPlan(ctx context.Context, client *passwork.Client, vaultId string, manifest Manifest, opts PlanOptions) (Plan, error) {
+	desired, err := manifest.resolve()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	live, err := client.SnapshotVault(ctx, vaultId)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := diff(manifest.Folders, desired, live, opts)
+	plan.VaultId = vaultId
+	return plan, nil
+}
+
+func diff(folders []string, desired []desiredItem, live passwork.ArchiveManifest, opts PlanOptions) Plan {
+	var plan Plan
+
+	// Folders
+	plan.folderIds = make(map[string]string)
+	for _, folder := range live.Folders {
+		plan.folderIds[strings.Join(folder.Path, "/")] = folder.Id
+	}
+
+	wanted := make(map[string]bool)
+	var wantedPaths [][]string
+	addFolder := func(path []string) {
+		for depth := 1; depth <= len(path); depth++ {
+			key := strings.Join(path[:depth], "/")
+			if !wanted[key] {
+				wanted[key] = true
+				wantedPaths = append(wantedPaths, path[:depth])
+			}
+		}
+	}
+	for _, folder := range folders {
+		addFolder(splitPath(folder))
+	}
+	for _, item := range desired {
+		addFolder(item.folder)
+	}
+
+	// Parents first
+	slices.SortStableFunc(wantedPaths, func(a, b []string) int { return len(a) - len(b) })
+	for _, path := range wantedPaths {
+		if _, ok := plan.folderIds[strings.Join(path, "/")]; !ok {
+			plan.Actions = append(plan.Actions, Action{Type: Create, Kind: "folder", Path: joinPath(path), folder: path})
+		}
+	}
+
+	// Items
+	folderPaths := make(map[string][]string)
+	for _, folder := range live.Folders {
+		folderPaths[folder.Id] = folder.Path
+	}
+	liveItems := make(map[string]passwork.ArchiveItem)
+	for _, item := range live.Items {
+		liveItems[itemKey(folderPaths[item.FolderId], item.Name)] = item
+	}
+
+	managed := make(map[string]bool)
+	for _, item := range desired {
+		key := itemKey(item.folder, item.request.Name)
+		managed[key] = true
+
+		item.request.CryptedPassword = passwork.NewSecret(base64.StdEncoding.EncodeToString([]byte(item.secret)))
+		current, ok := liveItems[key]
+		if !ok {
+			plan.Actions = append(plan.Actions, Action{Type: Create, Kind: "item", Path: joinPath(item.folder, item.request.Name), folder: item.folder, request: item.request})
+			continue
+		}
+
+		if changes := changedFields(item, current); len(changes) > 0 {
+			plan.Actions = append(plan.Actions, Action{Type: Update, Kind: "item", Path: joinPath(item.folder, item.request.Name), Id: current.Id, Changes: changes, folder: item.folder, request: item.request})
+		}
+	}
+
+	if !opts.Prune {
+		return plan
+	}
+
+	for _, item := range live.Items {
+		path := folderPaths[item.FolderId]
+		if !managed[itemKey(path, item.Name)] {
+			plan.Actions = append(plan.Actions, Action{Type: Delete, Kind: "item", Path: joinPath(path, item.Name), Id: item.Id})
+		}
+	}
+
+	// Children first, so no folder is deleted together with managed content
+	unmanaged := slices.Clone(live.Folders)
+	slices.SortStableFunc(unmanaged, func(a, b passwork.ArchiveFolder) int { return len(b.Path) - len(a.Path) })
+	for _, folder := range unmanaged {
+		if !wanted[strings.Join(folder.Path, "/")] {
+			plan.Actions = append(plan.Actions, Action{Type: Delete, Kind: "folder", Path: joinPath(folder.Path), Id: folder.Id})
+		}
+	}
+
+	return plan
+}
+
+func changedFields(desired desiredItem, current passwork.ArchiveItem) []string {
+	var changes []string
+	if desired.request.Login != current.Login {
+		changes = append(changes, "login")
+	}
+	if desired.secret != current.Password.Reveal() {
+		changes = append(changes, "password")
+	}
+	if desired.request.Url != current.Url {
+		changes = append(changes, "url")
+	}
+	if desired.request.Description != current.Description {
+		changes = append(changes, "description")
+	}
+	if desired.request.Color != current.Color {
+		changes = append(changes, "color")
+	}
+	if !sameTags(desired.request.Tags, current.Tags) {
+		changes = append(changes, "tags")
+	}
+	if !slices.EqualFunc(normalCustom(desired.request.Custom), normalCustom(current.Custom), passwork.PasswordCustomData.Equal) {
+		changes = append(changes, "custom")
+	}
+	return changes
+}
+
+// normalCustom sorts custom fields by name and defaults their type to text,
+// so that specs listing fields in another order or without types match
+func normalCustom(custom []passwork.PasswordCustomData) []passwork.PasswordCustomData {
+	custom = slices.Clone(custom)
+	for i := range custom {
+		if custom[i].Type == "" {
+			custom[i].Type = "text"
+		}
+	}
+	slices.SortStableFunc(custom, func(a, b passwork.PasswordCustomData) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return custom
+}
+
+func sameTags(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
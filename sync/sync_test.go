@@ -0,0 +1,203 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+const testManifest = `
+folders:
+  - infra/empty
+items:
+  - folder: infra/databases
+    name: postgres
+    login: admin
+    password:
+      env: SYNC_TEST_PASSWORD
+    tags: [db, prod]
+  - name: api
+    password: literal
+    custom:
+      - name: token
+        type: password
+        value:
+          file: %s
+`
+
+func loadTestManifest(t *testing.T) Manifest {
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("tok\n"), 0600))
+	t.Setenv("SYNC_TEST_PASSWORD", "pg-pass")
+
+	manifest, err := LoadManifest(strings.NewReader(strings.Replace(testManifest, "%s", file, 1)))
+	require.NoError(t, err)
+	return manifest
+}
+
+func TestLoadManifest(t *testing.T) {
+	manifest := loadTestManifest(t)
+
+	items, err := manifest.resolve()
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, []string{"infra", "databases"}, items[0].folder)
+	assert.Equal(t, "pg-pass", items[0].secret)
+	assert.Equal(t, "literal", items[1].secret)
+	assert.Equal(t, "tok", items[1].request.Custom[0].Value.Reveal())
+}
+
+func TestLoadManifestJSON(t *testing.T) {
+	manifest, err := LoadManifest(strings.NewReader(`{"items": [{"name": "a", "password": {"value": "x"}}]}`))
+	require.NoError(t, err)
+
+	items, err := manifest.resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "x", items[0].secret)
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	_, err := LoadManifest(strings.NewReader("items:\n  - name: a\n  - name: a\n"))
+	assert.ErrorContains(t, err, "duplicate item")
+
+	_, err = LoadManifest(strings.NewReader("items:\n  - login: a\n"))
+	assert.ErrorContains(t, err, "without name")
+
+	_, err = LoadManifest(strings.NewReader("unknown: true\n"))
+	assert.Error(t, err)
+
+	manifest, err := LoadManifest(strings.NewReader("items:\n  - name: a\n    password: {env: SYNC_TEST_UNSET}\n"))
+	require.NoError(t, err)
+	_, err = manifest.resolve()
+	assert.ErrorContains(t, err, "SYNC_TEST_UNSET")
+}
+
+func actions(plan Plan) []string {
+	var result []string
+	for _, action := range plan.Actions {
+		result = append(result, action.String())
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	manifest := loadTestManifest(t)
+	desired, err := manifest.resolve()
+	require.NoError(t, err)
+
+	t.Run("empty vault", func(t *testing.T) {
+		plan := diff(manifest.Folders, desired, passwork.ArchiveManifest{}, PlanOptions{})
+
+		assert.Equal(t, []string{
+			"create folder infra",
+			"create folder infra/empty",
+			"create folder infra/databases",
+			"create item infra/databases/postgres",
+			"create item api",
+		}, actions(plan))
+	})
+
+	live := passwork.ArchiveManifest{
+		Folders: []passwork.ArchiveFolder{
+			{Id: "f1", Name: "infra", Path: []string{"infra"}},
+			{Id: "f2", ParentId: "f1", Name: "empty", Path: []string{"infra", "empty"}},
+			{Id: "f3", ParentId: "f1", Name: "databases", Path: []string{"infra", "databases"}},
+			{Id: "f4", Name: "legacy", Path: []string{"legacy"}},
+		},
+		Items: []passwork.ArchiveItem{
+			{Id: "i1", FolderId: "f3", Name: "postgres", Login: "admin", Password: passwork.NewSecret("old"), Tags: []string{"prod", "db"}},
+			{Id: "i2", Name: "api", Password: passwork.NewSecret("literal"), Custom: []passwork.PasswordCustomData{{Name: "token", Value: passwork.NewSecret("tok"), Type: "password"}}},
+			{Id: "i3", FolderId: "f4", Name: "unmanaged"},
+		},
+	}
+
+	t.Run("update", func(t *testing.T) {
+		plan := diff(manifest.Folders, desired, live, PlanOptions{})
+
+		assert.Equal(t, []string{"update item infra/databases/postgres (password)"}, actions(plan))
+		assert.NotContains(t, plan.String(), "pg-pass", "Plans must not contain secrets.")
+		assert.Equal(t, "i1", plan.Actions[0].Id)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		plan := diff(manifest.Folders, desired, live, PlanOptions{Prune: true})
+
+		assert.Equal(t, []string{
+			"update item infra/databases/postgres (password)",
+			"delete item legacy/unmanaged",
+			"delete folder legacy",
+		}, actions(plan))
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		live.Items[0].Password = passwork.NewSecret("pg-pass")
+		plan := diff(manifest.Folders, desired, live, PlanOptions{})
+
+		assert.True(t, plan.Empty())
+	})
+}
+
+func TestChangedFieldsCustom(t *testing.T) {
+	current := passwork.ArchiveItem{Custom: []passwork.PasswordCustomData{
+		{Name: "port", Value: passwork.NewSecret("5432"), Type: "text"},
+		{Name: "host", Value: passwork.NewSecret("db"), Type: "text"},
+		{Name: "token", Value: passwork.NewSecret("tok"), Type: "password"},
+	}}
+	desired := desiredItem{request: passwork.PasswordRequest{Custom: []passwork.PasswordCustomData{
+		{Name: "token", Value: passwork.NewSecret("tok"), Type: "password"},
+		{Name: "host", Value: passwork.NewSecret("db")},
+		{Name: "port", Value: passwork.NewSecret("5432")},
+	}}}
+
+	assert.Empty(t, changedFields(desired, current), "Order and omitted text types should not cause updates.")
+
+	desired.request.Custom[1].Value = passwork.NewSecret("db2")
+	assert.Equal(t, []string{"custom"}, changedFields(desired, current))
+	desired.request.Custom[1].Value = passwork.NewSecret("db")
+	desired.request.Custom[2].Type = "password"
+	assert.Equal(t, []string{"custom"}, changedFields(desired, current))
+}
+
+func TestApplyFailedParent(t *testing.T) {
+	var created []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		name, _ := body["name"].(string)
+		if name == "infra" {
+			w.Write([]byte(`{"status":"error","code":"accessDenied"}`))
+			return
+		}
+		created = append(created, name)
+		w.Write([]byte(`{"status":"success","data":{"id":"new"}}`))
+	}))
+	defer server.Close()
+
+	plan := Plan{VaultId: "v1", Actions: []Action{
+		{Type: Create, Kind: "folder", Path: "infra", folder: []string{"infra"}},
+		{Type: Create, Kind: "folder", Path: "infra/empty", folder: []string{"infra", "empty"}},
+		{Type: Create, Kind: "item", Path: "infra/api", folder: []string{"infra"}, request: passwork.PasswordRequest{Name: "api"}},
+		{Type: Create, Kind: "folder", Path: "other", folder: []string{"other"}},
+	}}
+	client := passwork.NewClient(server.URL, "key", time.Second)
+
+	result, err := Apply(context.Background(), client, plan, ApplyOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, result.Failed, 3)
+	assert.ErrorIs(t, result.Failed[1].Err, errFolderMissing)
+	assert.ErrorIs(t, result.Failed[2].Err, errFolderMissing)
+	assert.Equal(t, []string{"other"}, created)
+}
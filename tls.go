@@ -0,0 +1,235 @@
+package passwork
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultTLSReloadInterval = 30 * time.Second
+
+type TLSOptions struct {
+	// CAFile and CA are PEM encoded CA certificates trusted instead of the system roots
+	CAFile string
+	CA     []byte
+
+	// CertFile and KeyFile or Cert and Key are the PEM encoded client certificate and key
+	CertFile string
+	KeyFile  string
+	Cert     []byte
+	Key      []byte
+
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13. Defaults to TLS 1.2.
+	MinVersion uint16
+
+	// Pins are base64 encoded SHA-256 hashes of a SubjectPublicKeyInfo, optionally
+	// prefixed with "sha256/". Connections are refused unless a certificate of
+	// the verified chain matches one of them.
+	Pins []string
+
+	// ReloadInterval is how often CAFile, CertFile and KeyFile are checked for
+	// changes, defaults to 30 seconds. A negative interval disables reloading.
+	ReloadInterval time.Duration
+}
+
+// PinMismatchError is returned when no certificate presented by the server matches a pin
+type PinMismatchError struct {
+	Host string
+
+	// Presented are the pins of the certificates of the verified chains
+	Presented []string
+}
+
+func (e *PinMismatchError) Error() string {
+	return fmt.Sprintf("passwork: certificate of %s doesn't match any pin, presented %s", e.Host, strings.Join(e.Presented, ", "))
+}
+
+// TLSTransport is an http.RoundTripper using the TLS options. Changed
+// certificate files are loaded by the first request after ReloadInterval,
+// if they can't be loaded the previous certificates stay in use.
+type TLSTransport struct {
+	opts TLSOptions
+	pins map[string]bool
+
+	mu        sync.Mutex
+	transport *http.Transport
+	files     map[string]fileVersion
+	checked   time.Time
+}
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewTLSTransport loads the certificates of opts
+func NewTLSTransport(opts TLSOptions) (*TLSTransport, error) {
+	if (opts.CertFile == "") != (opts.KeyFile == "") || (len(opts.Cert) == 0) != (len(opts.Key) == 0) {
+		return nil, errors.New("passwork: client certificate and key must be given together")
+	}
+	if opts.CertFile != "" && len(opts.Cert) > 0 {
+		return nil, errors.New("passwork: client certificate given as file and bytes")
+	}
+	if opts.MinVersion == 0 {
+		opts.MinVersion = tls.VersionTLS12
+	}
+	if opts.ReloadInterval == 0 {
+		opts.ReloadInterval = defaultTLSReloadInterval
+	}
+
+	t := &TLSTransport{opts: opts}
+	if len(opts.Pins) > 0 {
+		t.pins = make(map[string]bool, len(opts.Pins))
+		for _, pin := range opts.Pins {
+			t.pins[strings.TrimPrefix(pin, "sha256/")] = true
+		}
+	}
+
+	files, err := t.fileVersions()
+	if err != nil {
+		return nil, err
+	}
+	if t.transport, err = t.newTransport(); err != nil {
+		return nil, err
+	}
+	t.files, t.checked = files, time.Now()
+
+	return t, nil
+}
+
+// WithTLS sends requests through transport
+func WithTLS(transport *TLSTransport) Option {
+	return func(c *Client) {
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// SPKIPin returns the pin of a certificate in the format of TLSOptions.Pins
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (t *TLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.current().RoundTrip(req)
+	// No server name is sent for IP addresses
+	var pinErr *PinMismatchError
+	if errors.As(err, &pinErr) && pinErr.Host == "" {
+		pinErr.Host = req.URL.Hostname()
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes idle connections of the underlying transport
+func (t *TLSTransport) CloseIdleConnections() {
+	t.current().CloseIdleConnections()
+}
+
+// current returns the transport, replacing it first if certificate files changed
+func (t *TLSTransport) current() *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.opts.ReloadInterval < 0 || len(t.files) == 0 || time.Since(t.checked) < t.opts.ReloadInterval {
+		return t.transport
+	}
+	t.checked = time.Now()
+
+	files, err := t.fileVersions()
+	if err != nil || maps.Equal(files, t.files) {
+		return t.transport
+	}
+	transport, err := t.newTransport()
+	if err != nil {
+		return t.transport
+	}
+
+	// Connections made with the old certificates are closed once idle
+	t.transport.CloseIdleConnections()
+	t.transport, t.files = transport, files
+	return t.transport
+}
+
+func (t *TLSTransport) fileVersions() (map[string]fileVersion, error) {
+	files := make(map[string]fileVersion)
+	for _, path := range []string{t.opts.CAFile, t.opts.CertFile, t.opts.KeyFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("passwork: %w", err)
+		}
+		files[path] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+	}
+	return files, nil
+}
+
+func (t *TLSTransport) newTransport() (*http.Transport, error) {
+	config := &tls.Config{MinVersion: t.opts.MinVersion}
+
+	if t.opts.CAFile != "" || len(t.opts.CA) > 0 {
+		bundle := slices.Clone(t.opts.CA)
+		if t.opts.CAFile != "" {
+			data, err := os.ReadFile(t.opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("passwork: read CA bundle: %w", err)
+			}
+			bundle = append(append(bundle, '\n'), data...)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, errors.New("passwork: CA bundle contains no certificates")
+		}
+	}
+
+	var cert tls.Certificate
+	var err error
+	switch {
+	case t.opts.CertFile != "":
+		cert, err = tls.LoadX509KeyPair(t.opts.CertFile, t.opts.KeyFile)
+	case len(t.opts.Cert) > 0:
+		cert, err = tls.X509KeyPair(t.opts.Cert, t.opts.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("passwork: load client certificate: %w", err)
+	}
+	if len(cert.Certificate) > 0 {
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.pins != nil {
+		config.VerifyConnection = t.verifyPins
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// verifyPins runs after the chain was verified against the trusted roots
+func (t *TLSTransport) verifyPins(state tls.ConnectionState) error {
+	var presented []string
+	for _, chain := range state.VerifiedChains {
+		for _, cert := range chain {
+			pin := SPKIPin(cert)
+			if t.pins[pin] {
+				return nil
+			}
+			if !slices.Contains(presented, pin) {
+				presented = append(presented, pin)
+			}
+		}
+	}
+	return &PinMismatchError{Host: state.ServerName, Presented: presented}
+}
@@ -0,0 +1,214 @@
+package passwork
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, server bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := template, key
+	switch {
+	case parent == nil:
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign
+	case server:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		signer, signerKey = parent.cert, parent.key
+	default:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// newMTLSServer starts a server trusting client certificates issued by clientCA
+func newMTLSServer(t *testing.T, serverCert, clientCA *testCert, maxVersion uint16) *httptest.Server {
+	t.Helper()
+	pair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"id":"pw1"}}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MaxVersion:   maxVersion,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestTLSTransport(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, false)
+	server := newMTLSServer(t, newTestCert(t, "server", ca, true), ca, 0)
+	client := newTestCert(t, "client", ca, false)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, ca.certPEM, time.Now())
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		transport, err := NewTLSTransport(TLSOptions{CAFile: caFile, Cert: client.certPEM, Key: client.keyPEM})
+		require.NoError(t, err)
+
+		_, err = NewClient(server.URL, "key", time.Second, WithTLS(transport)).GetPassword("pw1")
+		require.NoError(t, err)
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		transport, err := NewTLSTransport(TLSOptions{CA: ca.certPEM})
+		require.NoError(t, err)
+
+		_, err = NewClient(server.URL, "key", time.Second, WithTLS(transport)).GetPassword("pw1")
+		require.Error(t, err)
+	})
+
+	t.Run("untrusted server", func(t *testing.T) {
+		transport, err := NewTLSTransport(TLSOptions{CA: newTestCert(t, "other", nil, false).certPEM, Cert: client.certPEM, Key: client.keyPEM})
+		require.NoError(t, err)
+
+		_, err = NewClient(server.URL, "key", time.Second, WithTLS(transport)).GetPassword("pw1")
+		var certErr *tls.CertificateVerificationError
+		require.ErrorAs(t, err, &certErr)
+	})
+
+	t.Run("minimum version", func(t *testing.T) {
+		old := newMTLSServer(t, newTestCert(t, "server", ca, true), ca, tls.VersionTLS12)
+		transport, err := NewTLSTransport(TLSOptions{CA: ca.certPEM, Cert: client.certPEM, Key: client.keyPEM, MinVersion: tls.VersionTLS13})
+		require.NoError(t, err)
+
+		_, err = NewClient(old.URL, "key", time.Second, WithTLS(transport)).GetPassword("pw1")
+		require.ErrorContains(t, err, "protocol version")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewTLSTransport(TLSOptions{CertFile: "cert.pem"})
+		assert.EqualError(t, err, "passwork: client certificate and key must be given together")
+		_, err = NewTLSTransport(TLSOptions{CA: []byte("not a certificate")})
+		assert.EqualError(t, err, "passwork: CA bundle contains no certificates")
+		_, err = NewTLSTransport(TLSOptions{CAFile: filepath.Join(dir, "missing.pem")})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestTLSPinning(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, false)
+	serverCert := newTestCert(t, "server", ca, true)
+	server := newMTLSServer(t, serverCert, ca, 0)
+	client := newTestCert(t, "client", ca, false)
+
+	for name, pin := range map[string]string{"leaf": SPKIPin(serverCert.cert), "ca": "sha256/" + SPKIPin(ca.cert)} {
+		t.Run(name, func(t *testing.T) {
+			transport, err := NewTLSTransport(TLSOptions{CA: ca.certPEM, Cert: client.certPEM, Key: client.keyPEM, Pins: []string{pin}})
+			require.NoError(t, err)
+
+			_, err = NewClient(server.URL, "key", time.Second, WithTLS(transport)).GetPassword("pw1")
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("mismatch", func(t *testing.T) {
+		other := newTestCert(t, "other", nil, false)
+		transport, err := NewTLSTransport(TLSOptions{CA: ca.certPEM, Cert: client.certPEM, Key: client.keyPEM, Pins: []string{SPKIPin(other.cert)}})
+		require.NoError(t, err)
+
+		snapshot, err := newOfflineSnapshot(filepath.Join(t.TempDir(), "snapshot"), "passphrase", 1, nil)
+		require.NoError(t, err)
+		_, err = NewClient(server.URL, "key", time.Second, WithTLS(transport), WithOfflineSnapshot(snapshot)).GetPassword("pw1")
+
+		var pinErr *PinMismatchError
+		require.ErrorAs(t, err, &pinErr)
+		assert.Equal(t, "127.0.0.1", pinErr.Host)
+		assert.ElementsMatch(t, []string{SPKIPin(serverCert.cert), SPKIPin(ca.cert)}, pinErr.Presented)
+		assert.NotErrorIs(t, err, ErrOffline)
+	})
+}
+
+func TestTLSReload(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, false)
+	server := newMTLSServer(t, newTestCert(t, "server", ca, true), ca, 0)
+	untrusted := newTestCert(t, "client", newTestCert(t, "other", nil, false), false)
+	trusted := newTestCert(t, "client", ca, false)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	written := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, untrusted.certPEM, written)
+	writeFile(t, keyFile, untrusted.keyPEM, written)
+
+	transport, err := NewTLSTransport(TLSOptions{CA: ca.certPEM, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Millisecond})
+	require.NoError(t, err)
+	client := NewClient(server.URL, "key", time.Second, WithTLS(transport))
+
+	_, err = client.GetPassword("pw1")
+	require.Error(t, err)
+
+	// A half written key is ignored until the pair matches again
+	writeFile(t, certFile, trusted.certPEM, written.Add(time.Second))
+	time.Sleep(2 * time.Millisecond)
+	_, err = client.GetPassword("pw1")
+	require.Error(t, err)
+
+	writeFile(t, keyFile, trusted.keyPEM, written.Add(2*time.Second))
+	time.Sleep(2 * time.Millisecond)
+	_, err = client.GetPassword("pw1")
+	require.NoError(t, err)
+}
@@ -2,8 +2,8 @@ package passwork
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,12 +11,30 @@ import (
 )
 
 func (c *Client) GetVault(vaultId string) (VaultResponse, error) {
-	url := fmt.Sprintf("%s/vaults/%s", c.BaseURL, vaultId)
-	method := http.MethodGet
+	return c.GetVaultContext(context.Background(), vaultId)
+}
+
+// GetVaultContext is GetVault bound to ctx
+func (c *Client) GetVaultContext(ctx context.Context, vaultId string) (VaultResponse, error) {
+	call := &Call{
+		Operation: "GetVault",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/vaults/%s", c.BaseURL, vaultId),
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (VaultResponse, error) {
+		return c.getVault(ctx, call, vaultId)
+	})
+}
+
+func (c *Client) getVault(ctx context.Context, call *Call, vaultId string) (VaultResponse, error) {
 	var responseObject VaultResponse
 	var err error
 
-	response, _, err := c.sendRequest(method, url, nil)
+	if c.cacheLookup(cacheKindVault, vaultId, &responseObject) {
+		return responseObject, nil
+	}
+
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
 	if err != nil {
 		return responseObject, err
 	}
@@ -27,17 +45,33 @@ func (c *Client) GetVault(vaultId string) (VaultResponse, error) {
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheStore(cacheKindVault, vaultId, responseObject)
+
 	return responseObject, nil
 }
 
 func (c *Client) AddVault(vaultRequest VaultAddRequest) (VaultOperationResponse, error) {
-	url := fmt.Sprintf("%s/vaults", c.BaseURL)
-	method := http.MethodPost
+	call := &Call{
+		Operation: "AddVault",
+		Method:    http.MethodPost,
+		URL:       fmt.Sprintf("%s/vaults", c.BaseURL),
+		Request:   &vaultRequest,
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (VaultOperationResponse, error) {
+		return c.addVault(ctx, call)
+	})
+}
+
+func (c *Client) addVault(ctx context.Context, call *Call) (VaultOperationResponse, error) {
 	var responseObject VaultOperationResponse
-	var err error
+
+	vaultRequest, err := callRequest[VaultAddRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
 
 	body, err := json.Marshal(vaultRequest)
 	if err != nil {
@@ -45,7 +79,7 @@ func (c *Client) AddVault(vaultRequest VaultAddRequest) (VaultOperationResponse,
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -57,24 +91,39 @@ func (c *Client) AddVault(vaultRequest VaultAddRequest) (VaultOperationResponse,
 	}
 
 	if responseObject.Status != "success" && responseObject.Code != "vaultCreated" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
 	return responseObject, nil
 }
 
 func (c *Client) EditVault(vaultId string, request VaultEditRequest) (VaultOperationResponse, error) {
-	url := fmt.Sprintf("%s/vaults/%s", c.BaseURL, vaultId)
-	method := http.MethodPut
+	call := &Call{
+		Operation: "EditVault",
+		Method:    http.MethodPut,
+		URL:       fmt.Sprintf("%s/vaults/%s", c.BaseURL, vaultId),
+		Request:   &request,
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (VaultOperationResponse, error) {
+		return c.editVault(ctx, call, vaultId)
+	})
+}
+
+func (c *Client) editVault(ctx context.Context, call *Call, vaultId string) (VaultOperationResponse, error) {
 	var responseObject VaultOperationResponse
 
+	request, err := callRequest[VaultEditRequest](call)
+	if err != nil {
+		return responseObject, err
+	}
+
 	body, err := json.Marshal(request)
 	if err != nil {
 		return responseObject, err
 	}
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, bytes.NewReader(body))
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
 	if err != nil {
 		return responseObject, err
 	}
@@ -86,19 +135,30 @@ func (c *Client) EditVault(vaultId string, request VaultEditRequest) (VaultOpera
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheInvalidate(cacheKindVault, vaultId)
+
 	return responseObject, nil
 }
 
 func (c *Client) DeleteVault(vaultId string) (DeleteResponse, error) {
-	url := fmt.Sprintf("%s/vaults/%s", c.BaseURL, vaultId)
-	method := http.MethodDelete
+	call := &Call{
+		Operation: "DeleteVault",
+		Method:    http.MethodDelete,
+		URL:       fmt.Sprintf("%s/vaults/%s", c.BaseURL, vaultId),
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (DeleteResponse, error) {
+		return c.deleteVault(ctx, call, vaultId)
+	})
+}
+
+func (c *Client) deleteVault(ctx context.Context, call *Call, vaultId string) (DeleteResponse, error) {
 	var responseObject DeleteResponse
 
 	// HTTP request
-	response, _, err := c.sendRequest(method, url, nil)
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
 	if err != nil {
 		return responseObject, err
 	}
@@ -110,8 +170,129 @@ func (c *Client) DeleteVault(vaultId string) (DeleteResponse, error) {
 	}
 
 	if responseObject.Status != "success" {
-		return responseObject, errors.New(responseObject.Code)
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	c.cacheInvalidate(cacheKindVault, vaultId)
+
+	return responseObject, nil
+}
+
+// ListVaults List all vaults the current user has access to
+func (c *Client) ListVaults() (VaultListResponse, error) {
+	return c.ListVaultsContext(context.Background())
+}
+
+// ListVaultsContext is ListVaults bound to ctx
+func (c *Client) ListVaultsContext(ctx context.Context) (VaultListResponse, error) {
+	call := &Call{
+		Operation: "ListVaults",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/vaults", c.BaseURL),
+	}
+	return invoke(ctx, c, call, func(ctx context.Context) (VaultListResponse, error) {
+		return c.listVaults(ctx, call)
+	})
+}
+
+func (c *Client) listVaults(ctx context.Context, call *Call) (VaultListResponse, error) {
+	var responseObject VaultListResponse
+	var err error
+
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if err != nil {
+		return responseObject, err
+	}
+
+	responseObject, err = utils.ParseJSONResponse[VaultListResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// API v1 doesn't return Status field
+	if responseObject.Status != "" && responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
+
+// GetVaultSettings Get vault-level settings such as the password policy
+func (c *Client) GetVaultSettings(vaultId string) (VaultSettingsResponse, error) {
+	call := &Call{
+		Operation: "GetVaultSettings",
+		Method:    http.MethodGet,
+		URL:       fmt.Sprintf("%s/vaults/%s/settings", c.BaseURL, vaultId),
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (VaultSettingsResponse, error) {
+		return c.getVaultSettings(ctx, call, vaultId)
+	})
+}
+
+func (c *Client) getVaultSettings(ctx context.Context, call *Call, vaultId string) (VaultSettingsResponse, error) {
+	var responseObject VaultSettingsResponse
+	var err error
+
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, nil)
+	if err != nil {
+		return responseObject, err
+	}
+
+	responseObject, err = utils.ParseJSONResponse[VaultSettingsResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	if responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
+	}
+
+	return responseObject, nil
+}
+
+// UpdateVaultSettings Replace the vault-level settings
+func (c *Client) UpdateVaultSettings(vaultId string, settings VaultSettingsData) (VaultSettingsResponse, error) {
+	call := &Call{
+		Operation: "UpdateVaultSettings",
+		Method:    http.MethodPut,
+		URL:       fmt.Sprintf("%s/vaults/%s/settings", c.BaseURL, vaultId),
+		Request:   &settings,
+	}
+	return invoke(context.Background(), c, call, func(ctx context.Context) (VaultSettingsResponse, error) {
+		return c.updateVaultSettings(ctx, call, vaultId)
+	})
+}
+
+func (c *Client) updateVaultSettings(ctx context.Context, call *Call, vaultId string) (VaultSettingsResponse, error) {
+	var responseObject VaultSettingsResponse
+
+	settings, err := callRequest[VaultSettingsData](call)
+	if err != nil {
+		return responseObject, err
+	}
+
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return responseObject, err
+	}
+
+	// HTTP request
+	response, _, err := c.sendRequestContext(ctx, call.Method, call.URL, bytes.NewReader(body))
+	if err != nil {
+		return responseObject, err
+	}
+
+	// Parse JSON into struct
+	responseObject, err = utils.ParseJSONResponse[VaultSettingsResponse](response)
+	if err != nil {
+		return responseObject, err
+	}
+
+	if responseObject.Status != "success" {
+		return responseObject, &APIError{Code: responseObject.Code}
 	}
 
+	c.cacheInvalidate(cacheKindVault, vaultId)
+
 	return responseObject, nil
 }
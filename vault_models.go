@@ -1,11 +1,43 @@
 package passwork
 
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
 type VaultResponse struct {
 	Status string
 	Code   string
 	Data   VaultResponseData
 }
 
+type VaultListResponse struct {
+	Status string
+	Code   string
+	Data   []VaultResponseData
+}
+
+// UnmarshalJSON implements custom unmarshaling to support both API v1 and v4 formats
+// API v1 uses "items" field, API v4 uses "Data" field
+func (v *VaultListResponse) UnmarshalJSON(data []byte) error {
+	var v1Format struct {
+		Items []VaultResponseData `json:"items"`
+	}
+	if err := json.Unmarshal(data, &v1Format); err == nil && v1Format.Items != nil {
+		v.Data = v1Format.Items
+		return nil
+	}
+
+	type Alias VaultListResponse
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(v),
+	}
+	return json.Unmarshal(data, aux)
+}
+
 type VaultResponseData struct {
 	Id                   string
 	Name                 string
@@ -13,6 +45,90 @@ type VaultResponseData struct {
 	Access               string
 	Scope                string
 	Visible              bool // only present in response when IsPrivate = true
+	FoldersAmount        int
+	PasswordsAmount      int
+	Owner                User
+	CreatedAt            string // RFC 3339
+	Members              []VaultMemberData
+	Settings             VaultSettingsData
+}
+
+// UnmarshalJSON implements custom unmarshaling to support both API v1 and v4 formats
+// API v1 reports counts as "foldersCount"/"itemsCount", the owner as a plain user ID
+// and the creation date as a unix timestamp
+func (v *VaultResponseData) UnmarshalJSON(data []byte) error {
+	type Alias VaultResponseData
+	aux := &struct {
+		*Alias
+		Owner        json.RawMessage
+		CreatedAt    json.RawMessage
+		FoldersCount *int
+		ItemsCount   *int
+		ItemsAmount  *int
+	}{
+		Alias: (*Alias)(v),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	if aux.FoldersCount != nil {
+		v.FoldersAmount = *aux.FoldersCount
+	}
+	if aux.ItemsAmount != nil {
+		v.PasswordsAmount = *aux.ItemsAmount
+	}
+	if aux.ItemsCount != nil {
+		v.PasswordsAmount = *aux.ItemsCount
+	}
+
+	if len(aux.Owner) > 0 && string(aux.Owner) != "null" {
+		if err := json.Unmarshal(aux.Owner, &v.Owner); err != nil {
+			if err := json.Unmarshal(aux.Owner, &v.Owner.Id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(aux.CreatedAt) > 0 && string(aux.CreatedAt) != "null" {
+		if err := json.Unmarshal(aux.CreatedAt, &v.CreatedAt); err != nil {
+			timestamp, err := strconv.ParseInt(string(aux.CreatedAt), 10, 64)
+			if err != nil {
+				return err
+			}
+			v.CreatedAt = time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+		}
+	}
+
+	return nil
+}
+
+type VaultMemberData struct {
+	Id         string
+	Name       string
+	Email      string
+	Access     string
+	AccessCode int
+}
+
+type VaultSettingsResponse struct {
+	Status string
+	Code   string
+	Data   VaultSettingsData
+}
+
+type VaultSettingsData struct {
+	PasswordPolicy PasswordPolicyData `json:"passwordPolicy"`
+	DefaultColor   int                `json:"defaultColor"`
+	DefaultTags    []string           `json:"defaultTags,omitempty"`
+}
+
+type PasswordPolicyData struct {
+	MinLength        int  `json:"minLength"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigits    bool `json:"requireDigits"`
+	RequireSymbols   bool `json:"requireSymbols"`
 }
 
 type VaultAddRequest struct {
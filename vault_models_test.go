@@ -0,0 +1,55 @@
+package passwork
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultResponseDataUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want VaultResponseData
+	}{
+		{
+			name: "v4",
+			json: `{"id":"v1","name":"vault","foldersAmount":2,"passwordsAmount":5,"owner":{"id":"u1","name":"Jo","email":"jo@example.com"},"createdAt":"2024-03-17T10:00:00Z"}`,
+			want: VaultResponseData{
+				Id:              "v1",
+				Name:            "vault",
+				FoldersAmount:   2,
+				PasswordsAmount: 5,
+				Owner:           User{Id: "u1", Name: "Jo", Email: "jo@example.com"},
+				CreatedAt:       "2024-03-17T10:00:00Z",
+			},
+		},
+		{
+			name: "v1",
+			json: `{"id":"v1","name":"vault","foldersCount":2,"itemsCount":5,"owner":"u1","createdAt":1710669600}`,
+			want: VaultResponseData{
+				Id:              "v1",
+				Name:            "vault",
+				FoldersAmount:   2,
+				PasswordsAmount: 5,
+				Owner:           User{Id: "u1"},
+				CreatedAt:       "2024-03-17T10:00:00Z",
+			},
+		},
+		{
+			name: "missing optional fields",
+			json: `{"id":"v1","name":"vault","owner":null}`,
+			want: VaultResponseData{Id: "v1", Name: "vault"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got VaultResponseData
+			if assert.NoError(t, json.Unmarshal([]byte(tt.json), &got)) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -51,6 +51,45 @@ func (suite *PassworkTestSuite) TestVault() {
 		}
 	})
 
+	suite.Run("List", func() {
+		result, err := suite.client.ListVaults()
+
+		if suite.NoError(err) {
+			found := false
+			for _, vault := range result.Data {
+				if vault.Id == suite.VaultId {
+					found = true
+					suite.Equal(suite.VaultName, vault.Name, "Listed vault name should be the same as suite VaultName.")
+				}
+			}
+			suite.True(found, "ListVaults() should contain the created vault.")
+		}
+	})
+
+	suite.Run("Settings", func() {
+		settings := VaultSettingsData{
+			PasswordPolicy: PasswordPolicyData{
+				MinLength:      16,
+				RequireDigits:  true,
+				RequireSymbols: true,
+			},
+			DefaultColor: 3,
+		}
+
+		result, err := suite.client.UpdateVaultSettings(suite.VaultId, settings)
+
+		if suite.NoError(err) {
+			suite.Equal("success", result.Status, "UpdateVaultSettings() should return success.")
+		}
+
+		result, err = suite.client.GetVaultSettings(suite.VaultId)
+
+		if suite.NoError(err) {
+			suite.Equal("success", result.Status, "GetVaultSettings() should return success.")
+			suite.Equal(settings, result.Data, "Result settings should be the same as updated settings.")
+		}
+	})
+
 	suite.Run("Delete", func() {
 		result, err := suite.client.DeleteVault(suite.VaultId)
 
@@ -0,0 +1,355 @@
+package passwork
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval   = 30 * time.Second
+	defaultWatchMaxBackoff = 5 * time.Minute
+)
+
+// Watch Poll vaults, folders and items and emit an event for every change
+// The channel is closed once the context is cancelled. Events of a poll are
+// checkpointed after the last one was received, so a restarted watcher using
+// the same store doesn't repeat them.
+func (c *Client) Watch(ctx context.Context, spec WatchSpec) (<-chan WatchEvent, error) {
+	if len(spec.VaultIds) == 0 && len(spec.FolderIds) == 0 && len(spec.ItemIds) == 0 {
+		return nil, errors.New("watch: nothing to watch")
+	}
+	if spec.Interval <= 0 {
+		spec.Interval = defaultWatchInterval
+	}
+	if spec.MaxBackoff <= 0 {
+		spec.MaxBackoff = defaultWatchMaxBackoff
+	}
+	if spec.CheckpointKey == "" {
+		spec.CheckpointKey = "watch"
+	}
+
+	var known map[string]watchState
+	if spec.Checkpoints != nil {
+		data, err := spec.Checkpoints.LoadCheckpoint(spec.CheckpointKey)
+		if err != nil {
+			return nil, fmt.Errorf("watch: load checkpoint: %w", err)
+		}
+		if data != nil {
+			if err := json.Unmarshal(data, &known); err != nil {
+				return nil, fmt.Errorf("watch: decode checkpoint: %w", err)
+			}
+		}
+	}
+
+	// Cached responses would hide changes
+	client := c.Uncached()
+	events := make(chan WatchEvent)
+
+	go func() {
+		defer close(events)
+
+		send := func(event WatchEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		failures := 0
+		for {
+			wait := spec.Interval
+
+			current, err := client.watchPoll(ctx, spec)
+			if err == nil {
+				if known != nil || spec.EmitInitial {
+					for _, event := range watchChanges(known, current) {
+						if !send(event) {
+							return
+						}
+					}
+				}
+				known = current
+
+				if spec.Checkpoints != nil {
+					err = saveWatchCheckpoint(spec, known)
+				}
+			}
+
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !send(WatchEvent{Type: WatchError, Err: err}) {
+					return
+				}
+				failures++
+				wait = min(spec.Interval<<min(failures, 16), spec.MaxBackoff)
+			} else {
+				failures = 0
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func saveWatchCheckpoint(spec WatchSpec, known map[string]watchState) error {
+	data, err := json.Marshal(known)
+	if err != nil {
+		return err
+	}
+	if err := spec.Checkpoints.SaveCheckpoint(spec.CheckpointKey, data); err != nil {
+		return fmt.Errorf("watch: save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// watchPoll reads the current state of all watched objects. A poll fails as a
+// whole so that objects missing due to an error aren't reported as deleted.
+func (c *Client) watchPoll(ctx context.Context, spec WatchSpec) (map[string]watchState, error) {
+	current := make(map[string]watchState)
+
+	// Folders are watched through their vault, remember which ones to keep
+	vaultIds := slices.Clone(spec.VaultIds)
+	folderVaults := make(map[string]string)
+	for _, folderId := range spec.FolderIds {
+		folder, err := c.GetFolderContext(ctx, folderId)
+		if err != nil {
+			return nil, fmt.Errorf("watch: get folder %s: %w", folderId, err)
+		}
+		if folder.Stale {
+			return nil, ErrOffline
+		}
+		folderVaults[folderId] = folder.Data.VaultId
+		if !slices.Contains(vaultIds, folder.Data.VaultId) {
+			vaultIds = append(vaultIds, folder.Data.VaultId)
+		}
+	}
+
+	for _, vaultId := range vaultIds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		wholeVault := slices.Contains(spec.VaultIds, vaultId)
+
+		folders, err := c.searchFolderContext(ctx, FolderSearchRequest{VaultId: vaultId})
+		if err != nil {
+			return nil, fmt.Errorf("watch: list folders of vault %s: %w", vaultId, err)
+		}
+		for _, folder := range folders.Data {
+			if wholeVault || watchedFolder(spec.FolderIds, folder.Id, folder.Path) {
+				current[folder.Id] = folderWatchState(folder)
+			}
+		}
+
+		items, err := c.SearchPasswordContext(ctx, PasswordSearchRequest{VaultId: vaultId})
+		if err != nil {
+			return nil, fmt.Errorf("watch: list items of vault %s: %w", vaultId, err)
+		}
+		if items.Stale {
+			return nil, ErrOffline
+		}
+		for _, item := range items.Data {
+			if wholeVault || watchedFolder(spec.FolderIds, item.FolderId, item.Path) {
+				current[item.Id] = itemWatchState(item)
+			}
+		}
+	}
+
+	for _, itemId := range spec.ItemIds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item, err := c.GetPasswordContext(ctx, itemId)
+		if err != nil {
+			// The API reports deleted items with the passwordNull code
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.Code == "passwordNull" {
+				delete(current, itemId)
+				continue
+			}
+			return nil, fmt.Errorf("watch: get item %s: %w", itemId, err)
+		}
+		if item.Stale {
+			return nil, ErrOffline
+		}
+		current[itemId] = itemWatchState(item.Data)
+	}
+
+	return current, nil
+}
+
+// watchedFolder reports whether an object in folderId with the given path lies
+// within one of the watched folders
+func watchedFolder(folderIds []string, folderId string, path []PathData) bool {
+	for _, watched := range folderIds {
+		if watched == folderId {
+			return true
+		}
+		for _, segment := range path {
+			if segment.Type == "folder" && segment.Id == watched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func folderWatchState(folder FolderResponseData) watchState {
+	return watchState{
+		Kind:     WatchKindFolder,
+		VaultId:  folder.VaultId,
+		FolderId: folder.ParentId,
+		Name:     folder.Name,
+		Hash:     watchHash(folder.Name),
+	}
+}
+
+func itemWatchState(item PasswordResponseData) watchState {
+	attachments := make([]string, len(item.Attachments))
+	for i, attachment := range item.Attachments {
+		attachments[i] = attachment.Id
+	}
+	custom := make([]string, 0, 2*len(item.Custom))
+	for _, c := range item.Custom {
+		custom = append(custom, c.Name, c.Type)
+	}
+
+	// Secrets are left out of the hash, search results don't carry them and
+	// LastPasswordUpdate and UpdatedAt change with them
+	return watchState{
+		Kind:     WatchKindItem,
+		VaultId:  item.VaultId,
+		FolderId: item.FolderId,
+		Name:     item.Name,
+		Hash: watchHash(item.Name, item.Login, item.Description, item.Url,
+			item.Color, item.Tags, custom, attachments, item.LastPasswordUpdate, item.UpdatedAt),
+		Updated: item.UpdatedAt,
+	}
+}
+
+// watchHash hashes the given fields to detect changes. Checkpoints hold names
+// and ids in the clear, never pass secrets.
+func watchHash(fields ...any) string {
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// watchChanges compares two polls and returns the resulting events
+func watchChanges(previous, current map[string]watchState) []WatchEvent {
+	var events []WatchEvent
+	event := func(eventType WatchEventType, id string, state watchState) WatchEvent {
+		return WatchEvent{
+			Type:      eventType,
+			Kind:      state.Kind,
+			Id:        id,
+			VaultId:   state.VaultId,
+			FolderId:  state.FolderId,
+			Name:      state.Name,
+			UpdatedAt: state.Updated,
+		}
+	}
+
+	for _, id := range sortedKeys(current) {
+		state := current[id]
+		before, ok := previous[id]
+		if !ok {
+			events = append(events, event(WatchCreated, id, state))
+			continue
+		}
+		if before.FolderId != state.FolderId {
+			moved := event(WatchMoved, id, state)
+			moved.PreviousFolderId = before.FolderId
+			events = append(events, moved)
+		}
+		if before.Hash != state.Hash {
+			events = append(events, event(WatchUpdated, id, state))
+		}
+	}
+
+	for _, id := range sortedKeys(previous) {
+		if _, ok := current[id]; !ok {
+			events = append(events, event(WatchDeleted, id, previous[id]))
+		}
+	}
+
+	return events
+}
+
+func sortedKeys(states map[string]watchState) []string {
+	keys := make([]string, 0, len(states))
+	for key := range states {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// FileCheckpointStore keeps watcher checkpoints as files in a directory
+type FileCheckpointStore struct {
+	Dir string
+}
+
+func (s FileCheckpointStore) LoadCheckpoint(key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s FileCheckpointStore) SaveCheckpoint(key string, data []byte) error {
+	tmp, err := os.CreateTemp(s.Dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.Dir, key+".json"))
+}
+
+// MemoryCheckpointStore keeps watcher checkpoints in memory
+type MemoryCheckpointStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *MemoryCheckpointStore) LoadCheckpoint(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *MemoryCheckpointStore) SaveCheckpoint(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		s.data = make(map[string][]byte)
+	}
+	s.data[key] = data
+	return nil
+}
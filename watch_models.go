@@ -0,0 +1,76 @@
+package passwork
+
+import "time"
+
+type WatchEventType string
+
+const (
+	WatchCreated WatchEventType = "created"
+	WatchUpdated WatchEventType = "updated"
+	WatchDeleted WatchEventType = "deleted"
+	WatchMoved   WatchEventType = "moved"
+	WatchError   WatchEventType = "error" // Polling failed, the watcher backs off and retries
+)
+
+type WatchKind string
+
+const (
+	WatchKindItem   WatchKind = "item"
+	WatchKindFolder WatchKind = "folder"
+)
+
+type WatchSpec struct {
+	// VaultIds watches all folders and items of these vaults
+	VaultIds []string
+
+	// FolderIds watches these folders, their subfolders and the items inside them
+	FolderIds []string
+
+	// ItemIds watches single items. Secrets aren't compared, password changes
+	// are detected through LastPasswordUpdate and UpdatedAt of the item.
+	ItemIds []string
+
+	// Interval between polls, defaults to 30 seconds
+	Interval time.Duration
+
+	// MaxBackoff caps the wait after failed polls, defaults to 5 minutes
+	MaxBackoff time.Duration
+
+	// Checkpoints persists the last seen state so a restarted watcher only
+	// reports changes made in between. Without it the first poll is a baseline.
+	Checkpoints CheckpointStore
+
+	// CheckpointKey identifies the watcher in the store, defaults to "watch"
+	CheckpointKey string
+
+	// EmitInitial reports all objects of the baseline poll as created
+	EmitInitial bool
+}
+
+type WatchEvent struct {
+	Type             WatchEventType
+	Kind             WatchKind
+	Id               string
+	VaultId          string
+	FolderId         string // Folder of the item or parent of the folder
+	PreviousFolderId string // Set for WatchMoved
+	Name             string
+	UpdatedAt        string
+	Err              error // Set for WatchError
+}
+
+// CheckpointStore persists watcher state between restarts
+type CheckpointStore interface {
+	LoadCheckpoint(key string) ([]byte, error) // Returns nil if there is no checkpoint yet
+	SaveCheckpoint(key string, data []byte) error
+}
+
+// watchState is the last seen state of a watched object
+type watchState struct {
+	Kind     WatchKind
+	VaultId  string
+	FolderId string
+	Name     string
+	Hash     string
+	Updated  string
+}
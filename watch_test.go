@@ -0,0 +1,212 @@
+package passwork
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type watchTestServer struct {
+	mu      sync.Mutex
+	items   map[string]PasswordResponseData
+	failing bool
+}
+
+func newWatchTestServer(t *testing.T) (*httptest.Server, *watchTestServer) {
+	state := &watchTestServer{items: map[string]PasswordResponseData{
+		"pw1": {Id: "pw1", VaultId: "v1", FolderId: "f1", Name: "database", UpdatedAt: "1"},
+		"pw2": {Id: "pw2", VaultId: "v1", FolderId: "f1", Name: "mail", UpdatedAt: "1"},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		if state.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		switch r.URL.Path {
+		case "/folders/search":
+			w.Write([]byte(`{"status":"success","data":[{"id":"f1","vaultId":"v1","name":"infra"},{"id":"f2","vaultId":"v1","name":"apps"}]}`))
+		case "/items/search":
+			items := make([]PasswordResponseData, 0, len(state.items))
+			for _, item := range state.items {
+				items = append(items, item)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": items})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, state
+}
+
+func (s *watchTestServer) update(fn func(items map[string]PasswordResponseData)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.items)
+}
+
+func receiveWatchEvents(t *testing.T, events <-chan WatchEvent, n int) []WatchEvent {
+	var received []WatchEvent
+	for len(received) < n {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of %d events: %+v", len(received), n, received)
+		}
+	}
+	return received
+}
+
+func TestWatch(t *testing.T) {
+	server, state := newWatchTestServer(t)
+	client := NewClient(server.URL, "key", time.Second)
+	store := &MemoryCheckpointStore{}
+	spec := WatchSpec{VaultIds: []string{"v1"}, Interval: 20 * time.Millisecond, Checkpoints: store}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := client.Watch(ctx, spec)
+	require.NoError(t, err)
+
+	// Wait for the baseline to be checkpointed
+	require.Eventually(t, func() bool {
+		data, _ := store.LoadCheckpoint("watch")
+		return data != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	state.update(func(items map[string]PasswordResponseData) {
+		pw1 := items["pw1"]
+		pw1.FolderId = "f2"
+		pw1.UpdatedAt = "2"
+		items["pw1"] = pw1
+		delete(items, "pw2")
+		items["pw3"] = PasswordResponseData{Id: "pw3", VaultId: "v1", Name: "new"}
+	})
+
+	received := receiveWatchEvents(t, events, 4)
+	assert.Equal(t, WatchMoved, received[0].Type)
+	assert.Equal(t, "pw1", received[0].Id)
+	assert.Equal(t, "f1", received[0].PreviousFolderId)
+	assert.Equal(t, "f2", received[0].FolderId)
+	assert.Equal(t, WatchUpdated, received[1].Type)
+	assert.Equal(t, "pw1", received[1].Id)
+	assert.Equal(t, WatchCreated, received[2].Type)
+	assert.Equal(t, "pw3", received[2].Id)
+	assert.Equal(t, WatchDeleted, received[3].Type)
+	assert.Equal(t, "pw2", received[3].Id)
+	assert.Equal(t, WatchKindItem, received[3].Kind)
+
+	cancel()
+	for range events {
+	}
+
+	t.Run("Restart", func(t *testing.T) {
+		// Only the change made while the watcher was down is reported
+		state.update(func(items map[string]PasswordResponseData) {
+			pw3 := items["pw3"]
+			pw3.Name = "renamed"
+			items["pw3"] = pw3
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events, err := client.Watch(ctx, spec)
+		require.NoError(t, err)
+
+		received := receiveWatchEvents(t, events, 1)
+		assert.Equal(t, WatchUpdated, received[0].Type)
+		assert.Equal(t, "pw3", received[0].Id)
+		assert.Equal(t, "renamed", received[0].Name)
+
+		select {
+		case event := <-events:
+			t.Fatalf("unexpected event %+v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		state.update(func(map[string]PasswordResponseData) { state.failing = true })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events, err := client.Watch(ctx, WatchSpec{FolderIds: []string{"f1"}, Interval: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		received := receiveWatchEvents(t, events, 2)
+		assert.Equal(t, WatchError, received[0].Type)
+		assert.Error(t, received[0].Err)
+	})
+}
+
+func TestWatchEmptySpec(t *testing.T) {
+	client := NewClient("http://localhost", "key", time.Second)
+	_, err := client.Watch(context.Background(), WatchSpec{})
+	assert.Error(t, err)
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	store := FileCheckpointStore{Dir: t.TempDir()}
+
+	data, err := store.LoadCheckpoint("watch")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	require.NoError(t, store.SaveCheckpoint("watch", []byte(`{}`)))
+	data, err = store.LoadCheckpoint("watch")
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+}
+
+func TestItemWatchStateSecrets(t *testing.T) {
+	item := PasswordResponseData{
+		Id:                 "pw1",
+		Name:               "database",
+		CryptedPassword:    NewSecret("c2VjcmV0"),
+		Custom:             []PasswordCustomData{{Name: "token", Type: "password", Value: NewSecret("tok")}},
+		LastPasswordUpdate: 1,
+	}
+	state := itemWatchState(item)
+
+	// A checkpoint doesn't change with secrets that were revealed or not
+	withoutSecrets := item
+	withoutSecrets.CryptedPassword = Secret{}
+	withoutSecrets.Custom = []PasswordCustomData{{Name: "token", Type: "password"}}
+	assert.Equal(t, state, itemWatchState(withoutSecrets))
+
+	rotated := item
+	rotated.CryptedPassword = NewSecret("bmV3")
+	rotated.LastPasswordUpdate = 2
+	assert.NotEqual(t, state.Hash, itemWatchState(rotated).Hash)
+}
+
+func TestWatchPollCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL, "key", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.watchPoll(ctx, WatchSpec{ItemIds: []string{"pw1"}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "Polling should stop when ctx is done.")
+}
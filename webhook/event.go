@@ -0,0 +1,74 @@
+// Package webhook receives Passwork event deliveries over HTTP.
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+type EventType string
+
+const (
+	ItemCreated   EventType = "item.created"
+	ItemUpdated   EventType = "item.updated"
+	ItemDeleted   EventType = "item.deleted"
+	ItemMoved     EventType = "item.moved"
+	FolderCreated EventType = "folder.created"
+	FolderUpdated EventType = "folder.updated"
+	FolderDeleted EventType = "folder.deleted"
+	VaultUpdated  EventType = "vault.updated"
+	VaultDeleted  EventType = "vault.deleted"
+
+	// AnyEvent registers a handler for all event types
+	AnyEvent EventType = "*"
+)
+
+// Event is a single delivery
+type Event struct {
+	Id       string          `json:"id"`
+	Type     EventType       `json:"event"`
+	VaultId  string          `json:"vaultId"`
+	FolderId string          `json:"folderId"`
+	ItemId   string          `json:"itemId"`
+	UserId   string          `json:"userId"`
+	Time     time.Time       `json:"-"`
+	Data     json.RawMessage `json:"data"` // Additional payload, depends on the event type
+
+	// Set when the handler enriches events through a client
+	Item   *passwork.PasswordResponseData `json:"-"`
+	Folder *passwork.FolderResponseData   `json:"-"`
+}
+
+// UnmarshalJSON decodes the timestamp given as unix seconds or RFC 3339
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type Alias Event
+	aux := &struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+		*Alias
+	}{Alias: (*Alias)(e)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	var unix int64
+	var formatted string
+	switch {
+	case len(aux.Timestamp) == 0:
+	case json.Unmarshal(aux.Timestamp, &unix) == nil:
+		e.Time = time.Unix(unix, 0).UTC()
+	case json.Unmarshal(aux.Timestamp, &formatted) == nil:
+		parsed, err := time.Parse(time.RFC3339, formatted)
+		if err != nil {
+			return err
+		}
+		e.Time = parsed
+	}
+	return nil
+}
+
+// deleted reports whether the object the event refers to is gone
+func (e Event) deleted() bool {
+	return e.Type == ItemDeleted || e.Type == FolderDeleted || e.Type == VaultDeleted
+}
@@ -0,0 +1,292 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body, optionally prefixed with "sha256="
+	SignatureHeader = "X-Passwork-Signature"
+
+	maxBodySize = 1 << 20
+
+	// DefaultTolerance is how far event timestamps may be from now unless
+	// WithTolerance changes it
+	DefaultTolerance = 5 * time.Minute
+
+	// defaultDedupWindow is how long event ids are remembered without a tolerance
+	defaultDedupWindow = 24 * time.Hour
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrExpired          = errors.New("webhook: event is too old")
+	ErrFuture           = errors.New("webhook: event timestamp is in the future")
+	ErrNoTimestamp      = errors.New("webhook: event timestamp missing")
+)
+
+// HandlerFunc processes an event, returning an error makes the sender retry the delivery
+type HandlerFunc func(ctx context.Context, event Event) error
+
+// Handler is an http.Handler that verifies, decodes and dispatches deliveries
+type Handler struct {
+	secret    []byte
+	client    *passwork.Client
+	tolerance time.Duration
+
+	mu       sync.RWMutex
+	handlers map[EventType][]HandlerFunc
+
+	// Ids of dispatched events and when they can be forgotten, and of events
+	// being dispatched
+	seenMu     sync.Mutex
+	seen       map[string]time.Time
+	inProgress map[string]bool
+}
+
+type Option func(*Handler)
+
+// WithClient enriches events with the referenced item and folder before dispatching
+func WithClient(client *passwork.Client) Option {
+	return func(h *Handler) {
+		h.client = client
+	}
+}
+
+// WithTolerance rejects events without a timestamp or whose timestamp is
+// more than d before or after now to limit replays, instead of
+// DefaultTolerance. Zero disables the check, events are then only
+// deduplicated by id for 24 hours.
+func WithTolerance(d time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = d
+	}
+}
+
+// NewHandler creates a handler verifying deliveries with the shared secret.
+// It panics if secret is empty, anyone could sign deliveries otherwise.
+func NewHandler(secret string, opts ...Option) *Handler {
+	if secret == "" {
+		panic("webhook: empty secret")
+	}
+	h := &Handler{
+		secret:     []byte(secret),
+		tolerance:  DefaultTolerance,
+		handlers:   make(map[EventType][]HandlerFunc),
+		seen:       make(map[string]time.Time),
+		inProgress: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// On registers fn for an event type, AnyEvent receives all events
+func (h *Handler) On(eventType EventType, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[eventType] = append(h.handlers[eventType], fn)
+}
+
+// Sign returns the signature header value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature header value of body
+func Verify(secret string, body []byte, signature string) error {
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event, err := h.Decode(body, r.Header.Get(SignatureHeader))
+	switch {
+	case errors.Is(err, ErrInvalidSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Redelivered events are acknowledged without dispatching them again,
+	// concurrent deliveries of an event are retried by the sender
+	switch h.begin(event.Id) {
+	case deliveryDone:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case deliveryInProgress:
+		http.Error(w, "event is being processed", http.StatusConflict)
+		return
+	}
+
+	// Handler errors can contain item details, they aren't sent back
+	err = h.Dispatch(r.Context(), event)
+	h.end(event, err == nil)
+	if err != nil {
+		http.Error(w, "event handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type deliveryState int
+
+const (
+	deliveryNew deliveryState = iota
+	deliveryInProgress
+	deliveryDone
+)
+
+// begin marks an event as being dispatched unless it was seen before.
+// Events without an id are always dispatched.
+func (h *Handler) begin(id string) deliveryState {
+	if id == "" {
+		return deliveryNew
+	}
+	h.seenMu.Lock()
+	defer h.seenMu.Unlock()
+
+	now := time.Now()
+	for seenId, expires := range h.seen {
+		if now.After(expires) {
+			delete(h.seen, seenId)
+		}
+	}
+	switch {
+	case h.inProgress[id]:
+		return deliveryInProgress
+	case !h.seen[id].IsZero():
+		return deliveryDone
+	}
+	h.inProgress[id] = true
+	return deliveryNew
+}
+
+// end remembers a successfully dispatched event, a failed one can be
+// redelivered. With a tolerance the id is kept until the event expires.
+func (h *Handler) end(event Event, dispatched bool) {
+	if event.Id == "" {
+		return
+	}
+	h.seenMu.Lock()
+	defer h.seenMu.Unlock()
+
+	delete(h.inProgress, event.Id)
+	if dispatched {
+		expires := time.Now().Add(defaultDedupWindow)
+		if h.tolerance > 0 {
+			expires = event.Time.Add(h.tolerance)
+			if now := time.Now(); expires.Before(now) {
+				expires = now
+			}
+		}
+		h.seen[event.Id] = expires
+	}
+}
+
+// Decode verifies the signature and decodes a delivery
+func (h *Handler) Decode(body []byte, signature string) (Event, error) {
+	var event Event
+	if err := Verify(string(h.secret), body, signature); err != nil {
+		return event, err
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return event, fmt.Errorf("webhook: decode event: %w", err)
+	}
+	if event.Type == "" {
+		return event, errors.New("webhook: event type missing")
+	}
+	if h.tolerance > 0 && event.Time.IsZero() {
+		return event, ErrNoTimestamp
+	}
+	if h.tolerance > 0 && time.Since(event.Time) > h.tolerance {
+		return event, ErrExpired
+	}
+	if h.tolerance > 0 && time.Until(event.Time) > h.tolerance {
+		return event, ErrFuture
+	}
+	return event, nil
+}
+
+// Dispatch enriches the event if a client is configured and calls the
+// handlers registered for its type, then those for AnyEvent
+func (h *Handler) Dispatch(ctx context.Context, event Event) error {
+	if h.client != nil && !event.deleted() {
+		if err := h.enrich(ctx, &event); err != nil {
+			return err
+		}
+	}
+
+	h.mu.RLock()
+	handlers := append(append([]HandlerFunc(nil), h.handlers[event.Type]...), h.handlers[AnyEvent]...)
+	h.mu.RUnlock()
+
+	var errs []error
+	for _, fn := range handlers {
+		if err := fn(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *Handler) enrich(ctx context.Context, event *Event) error {
+	if event.ItemId != "" {
+		item, err := h.client.GetPasswordContext(ctx, event.ItemId)
+		if err != nil {
+			return fmt.Errorf("webhook: get item %s: %w", event.ItemId, err)
+		}
+		event.Item = &item.Data
+		if event.FolderId == "" {
+			event.FolderId = item.Data.FolderId
+		}
+	}
+
+	if event.FolderId != "" {
+		folder, err := h.client.GetFolderContext(ctx, event.FolderId)
+		if err != nil {
+			return fmt.Errorf("webhook: get folder %s: %w", event.FolderId, err)
+		}
+		event.Folder = &folder.Data
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	passwork "github.com/treasure33/passwork-client-go"
+)
+
+const testSecret = "shared-secret"
+
+func deliver(handler http.Handler, body, signature string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	request.Header.Set(SignatureHeader, signature)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestHandlerDispatch(t *testing.T) {
+	// The events carry fixed timestamps
+	handler := NewHandler(testSecret, WithTolerance(0))
+
+	var updated, all []Event
+	handler.On(ItemUpdated, func(ctx context.Context, event Event) error {
+		updated = append(updated, event)
+		return nil
+	})
+	handler.On(AnyEvent, func(ctx context.Context, event Event) error {
+		all = append(all, event)
+		return nil
+	})
+
+	body := `{"id":"e1","event":"item.updated","vaultId":"v1","folderId":"f1","itemId":"pw1","userId":"u1","timestamp":1700000000}`
+	response := deliver(handler, body, Sign(testSecret, []byte(body)))
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	require.Len(t, updated, 1)
+	assert.Equal(t, "pw1", updated[0].ItemId)
+	assert.Equal(t, "v1", updated[0].VaultId)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), updated[0].Time)
+	assert.Nil(t, updated[0].Item)
+
+	body = `{"id":"e2","event":"folder.deleted","folderId":"f1","timestamp":"2024-01-02T03:04:05Z"}`
+	response = deliver(handler, body, Sign(testSecret, []byte(body)))
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Len(t, updated, 1)
+	require.Len(t, all, 2)
+	assert.Equal(t, FolderDeleted, all[1].Type)
+}
+
+func TestHandlerRejects(t *testing.T) {
+	handler := NewHandler(testSecret, WithTolerance(time.Minute))
+	handler.On(AnyEvent, func(ctx context.Context, event Event) error {
+		return errors.New("downstream unavailable")
+	})
+
+	body := fmt.Sprintf(`{"id":"e1","event":"item.created","itemId":"pw1","timestamp":%d}`, time.Now().Unix())
+
+	assert.Equal(t, http.StatusUnauthorized, deliver(handler, body, Sign("other-secret", []byte(body))).Code)
+	assert.Equal(t, http.StatusUnauthorized, deliver(handler, body, "").Code)
+	assert.Equal(t, http.StatusBadRequest, deliver(handler, "{", Sign(testSecret, []byte("{"))).Code)
+
+	old := `{"id":"e1","event":"item.created","itemId":"pw1","timestamp":1000}`
+	assert.Equal(t, http.StatusBadRequest, deliver(handler, old, Sign(testSecret, []byte(old))).Code)
+
+	undated := `{"id":"e1","event":"item.created","itemId":"pw1"}`
+	assert.Equal(t, http.StatusBadRequest, deliver(handler, undated, Sign(testSecret, []byte(undated))).Code)
+
+	future := fmt.Sprintf(`{"id":"e1","event":"item.created","itemId":"pw1","timestamp":%d}`, time.Now().Add(time.Hour).Unix())
+	_, err := handler.Decode([]byte(future), Sign(testSecret, []byte(future)))
+	assert.ErrorIs(t, err, ErrFuture)
+
+	_, err = NewHandler(testSecret).Decode([]byte(old), Sign(testSecret, []byte(old)))
+	assert.ErrorIs(t, err, ErrExpired, "Timestamps should be checked by default.")
+	assert.Panics(t, func() { NewHandler("") })
+
+	// Handler errors make the sender retry without exposing their details
+	response := deliver(handler, body, Sign(testSecret, []byte(body)))
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.NotContains(t, response.Body.String(), "downstream")
+
+	request := httptest.NewRequest(http.MethodGet, "/hook", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestHandlerEnrich(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/items/pw1":
+			w.Write([]byte(`{"status":"success","data":{"id":"pw1","vaultId":"v1","folderId":"f1","name":"database"}}`))
+		case "/folders/f1":
+			w.Write([]byte(`{"status":"success","data":{"id":"f1","vaultId":"v1","name":"infra"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := NewHandler(testSecret, WithClient(passwork.NewClient(server.URL, "key", time.Second)))
+	var received Event
+	handler.On(ItemMoved, func(ctx context.Context, event Event) error {
+		received = event
+		return nil
+	})
+
+	body := fmt.Sprintf(`{"id":"e1","event":"item.moved","vaultId":"v1","itemId":"pw1","timestamp":%d}`, time.Now().Unix())
+	response := deliver(handler, body, Sign(testSecret, []byte(body)))
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	require.NotNil(t, received.Item)
+	assert.Equal(t, "database", received.Item.Name)
+	assert.Equal(t, "f1", received.FolderId)
+	require.NotNil(t, received.Folder)
+	assert.Equal(t, "infra", received.Folder.Name)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := handler.Dispatch(ctx, Event{Type: ItemMoved, ItemId: "pw1"})
+	assert.ErrorIs(t, err, context.Canceled, "Enriching should use the request context.")
+}
+
+func TestHandlerDedup(t *testing.T) {
+	handler := NewHandler(testSecret)
+	var calls int
+	fail := true
+	handler.On(AnyEvent, func(ctx context.Context, event Event) error {
+		calls++
+		if fail {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+
+	body := fmt.Sprintf(`{"id":"e1","event":"item.created","itemId":"pw1","timestamp":%d}`, time.Now().Unix())
+	signature := Sign(testSecret, []byte(body))
+
+	assert.Equal(t, http.StatusInternalServerError, deliver(handler, body, signature).Code)
+	fail = false
+	assert.Equal(t, http.StatusNoContent, deliver(handler, body, signature).Code, "A failed event should be dispatched again.")
+	assert.Equal(t, http.StatusNoContent, deliver(handler, body, signature).Code)
+	assert.Equal(t, 2, calls, "A dispatched event should not be dispatched again.")
+
+	other := fmt.Sprintf(`{"id":"e2","event":"item.created","itemId":"pw1","timestamp":%d}`, time.Now().Unix())
+	deliver(handler, other, Sign(testSecret, []byte(other)))
+	assert.Equal(t, 3, calls)
+}